@@ -20,6 +20,10 @@ var (
 	errAesIvLenMustBeBlockSize               = errors.New("iv length must equal to block size")
 )
 
+// It is returned when the authentication tag of an authenticated mode does not match,
+// which means the key is wrong or the data has been tampered.
+var ErrAuthenticationFailed = errors.New("message authentication failed")
+
 // The key must be either 16, 24, or 32 bytes to select AES-128, AES-192, or AES-256.
 // The iv must be 16 bytes.
 // The block size of padding must be 16 bytes.
@@ -28,9 +32,9 @@ var (
 func NewAesCbcEncrypter(key, iv []byte, padding Padding) AesBlockModeEncrypter {
 	block, err := checkKeyIvPadding(key, iv, padding)
 	if err != nil {
-		return newAesBlockModeEncrypter(nil, nil, err)
+		return newAesBlockModeEncrypter(nil, nil, nil, nil, err)
 	}
-	return newAesBlockModeEncrypter(cipher.NewCBCEncrypter(block, iv), padding, nil)
+	return newAesBlockModeEncrypter(block, iv, cipher.NewCBCEncrypter, padding, nil)
 }
 
 // The key must be either 16, 24, or 32 bytes to select AES-128, AES-192, or AES-256.
@@ -41,9 +45,9 @@ func NewAesCbcEncrypter(key, iv []byte, padding Padding) AesBlockModeEncrypter {
 func NewAesCbcDecrypter(key, iv []byte, padding Padding) AesBlockModeDecrypter {
 	block, err := checkKeyIvPadding(key, iv, padding)
 	if err != nil {
-		return newAesBlockModeDecrypter(nil, nil, err)
+		return newAesBlockModeDecrypter(nil, nil, nil, nil, err)
 	}
-	return newAesBlockModeDecrypter(cipher.NewCBCDecrypter(block, iv), padding, nil)
+	return newAesBlockModeDecrypter(block, iv, cipher.NewCBCDecrypter, padding, nil)
 }
 
 // The key must be either 16, 24, or 32 bytes to select AES-128, AES-192, or AES-256.
@@ -53,9 +57,9 @@ func NewAesCbcDecrypter(key, iv []byte, padding Padding) AesBlockModeDecrypter {
 func NewAesCfbEncrypter(key, iv []byte) AesStream {
 	block, err := checkKeyIv(key, iv)
 	if err != nil {
-		return newAesStream(nil, err)
+		return newAesStream(nil, nil, nil, err)
 	}
-	return newAesStream(cipher.NewCFBEncrypter(block, iv), nil)
+	return newAesStream(block, iv, cipher.NewCFBEncrypter, nil)
 }
 
 // The key must be either 16, 24, or 32 bytes to select AES-128, AES-192, or AES-256.
@@ -65,9 +69,9 @@ func NewAesCfbEncrypter(key, iv []byte) AesStream {
 func NewAesCfbDecrypter(key, iv []byte) AesStream {
 	block, err := checkKeyIv(key, iv)
 	if err != nil {
-		return newAesStream(nil, err)
+		return newAesStream(nil, nil, nil, err)
 	}
-	return newAesStream(cipher.NewCFBDecrypter(block, iv), nil)
+	return newAesStream(block, iv, cipher.NewCFBDecrypter, nil)
 }
 
 // The key must be either 16, 24, or 32 bytes to select AES-128, AES-192, or AES-256.
@@ -77,9 +81,9 @@ func NewAesCfbDecrypter(key, iv []byte) AesStream {
 func NewAesOfb(key, iv []byte) AesStream {
 	block, err := checkKeyIv(key, iv)
 	if err != nil {
-		return newAesStream(nil, err)
+		return newAesStream(nil, nil, nil, err)
 	}
-	return newAesStream(cipher.NewOFB(block, iv), nil)
+	return newAesStream(block, iv, cipher.NewOFB, nil)
 }
 
 // The key must be either 16, 24, or 32 bytes to select AES-128, AES-192, or AES-256.
@@ -89,14 +93,14 @@ func NewAesOfb(key, iv []byte) AesStream {
 func NewAesCtr(key, iv []byte) AesStream {
 	block, err := checkKeyIv(key, iv)
 	if err != nil {
-		return newAesStream(nil, err)
+		return newAesStream(nil, nil, nil, err)
 	}
-	return newAesStream(cipher.NewCTR(block, iv), nil)
+	return newAesStream(block, iv, cipher.NewCTR, nil)
 }
 
 func checkKeyIv(key, iv []byte) (cipher.Block, error) {
-	if len(iv) != aes.BlockSize {
-		return nil, errAesIvLenMustBeBlockSize
+	if err := checkIv(iv); err != nil {
+		return nil, err
 	}
 	return aes.NewCipher(key)
 }
@@ -108,18 +112,39 @@ func checkKeyIvPadding(key, iv []byte, padding Padding) (cipher.Block, error) {
 	return checkKeyIv(key, iv)
 }
 
+func checkIv(iv []byte) error {
+	if len(iv) != aes.BlockSize {
+		return errAesIvLenMustBeBlockSize
+	}
+	return nil
+}
+
+// Create a cipher.BlockMode at the beginning of a message, such as cipher.NewCBCEncrypter.
+type newBlockModeFunc func(block cipher.Block, iv []byte) cipher.BlockMode
+
+// Create a cipher.Stream at the beginning of a message, such as cipher.NewCTR.
+type newStreamFunc func(block cipher.Block, iv []byte) cipher.Stream
+
+// Each call of Encrypt is an independent message which starts from the iv,
+// so the same plaintext is always encrypted to the same ciphertext with the same iv.
+// Use NewStream to encrypt a message part by part.
+//
 // It may has an error, call HasError to see it.
 type AesBlockModeEncrypter struct {
-	blockMode cipher.BlockMode
-	padding   Padding
-	err       error
+	block        cipher.Block
+	iv           []byte
+	newBlockMode newBlockModeFunc
+	padding      Padding
+	err          error
 }
 
-func newAesBlockModeEncrypter(blockMode cipher.BlockMode, padding Padding, err error) AesBlockModeEncrypter {
+func newAesBlockModeEncrypter(block cipher.Block, iv []byte, newBlockMode newBlockModeFunc, padding Padding, err error) AesBlockModeEncrypter {
 	return AesBlockModeEncrypter{
-		blockMode: blockMode,
-		padding:   padding,
-		err:       err,
+		block:        block,
+		iv:           append([]byte(nil), iv...),
+		newBlockMode: newBlockMode,
+		padding:      padding,
+		err:          err,
 	}
 }
 
@@ -127,28 +152,144 @@ func (e AesBlockModeEncrypter) HasError() (error, bool) {
 	return e.err, e.err != nil
 }
 
+// Encrypt src as a whole message from the iv.
+//
 // The result will not share the array of src.
 func (e AesBlockModeEncrypter) Encrypt(src []byte) ([]byte, error) {
 	if e.err != nil {
 		return nil, e.err
 	}
+	return e.encrypt(e.iv, src)
+}
+
+// Encrypt src as a whole message from the iv param instead of the configured one.
+// The iv must be 16 bytes.
+//
+// The result will not share the array of src.
+func (e AesBlockModeEncrypter) EncryptWithIv(iv, src []byte) ([]byte, error) {
+	if e.err != nil {
+		return nil, e.err
+	}
+	if err := checkIv(iv); err != nil {
+		return nil, err
+	}
+	return e.encrypt(iv, src)
+}
+
+func (e AesBlockModeEncrypter) encrypt(iv, src []byte) ([]byte, error) {
 	buf := e.padding.Pad(src)
-	e.blockMode.CryptBlocks(buf, buf)
+	if len(buf)%AesBlockSize != 0 { // Such as NoPadding with unaligned src.
+		return nil, errAesDataSizeMustBeMultipleOfBlockSize
+	}
+	e.newBlockMode(e.block, iv).CryptBlocks(buf, buf)
 	return buf, nil
 }
 
+// Replace the configured iv, which is used by the later Encrypt and NewStream.
+// The iv must be 16 bytes.
+func (e *AesBlockModeEncrypter) Reset(iv []byte) error {
+	if e.err != nil {
+		return e.err
+	}
+	if err := checkIv(iv); err != nil {
+		return err
+	}
+	e.iv = append([]byte(nil), iv...)
+	return nil
+}
+
+// Create a stream which encrypts a message part by part from the configured iv.
+// If e has error, the stream has the same error.
+func (e AesBlockModeEncrypter) NewStream() *AesBlockModeEncryptStream {
+	s := &AesBlockModeEncryptStream{
+		encrypter: e,
+	}
+	if e.err == nil {
+		s.blockMode = e.newBlockMode(e.block, e.iv)
+	}
+	return s
+}
+
+// It keeps the chaining state between calls, so that the parts of a message are encrypted
+// as if they were concatenated. Use it by pointer.
+//
 // It may has an error, call HasError to see it.
-type AesBlockModeDecrypter struct {
+type AesBlockModeEncryptStream struct {
+	encrypter AesBlockModeEncrypter
 	blockMode cipher.BlockMode
-	padding   Padding
-	err       error
 }
 
-func newAesBlockModeDecrypter(blockMode cipher.BlockMode, padding Padding, err error) AesBlockModeDecrypter {
+func (s *AesBlockModeEncryptStream) HasError() (error, bool) {
+	return s.encrypter.HasError()
+}
+
+// Encrypt the next part of the message without padding.
+// The size of src must be multiple of block size.
+//
+// The result will not share the array of src.
+func (s *AesBlockModeEncryptStream) EncryptBlocks(src []byte) ([]byte, error) {
+	if s.encrypter.err != nil {
+		return nil, s.encrypter.err
+	}
+	if len(src)%s.blockMode.BlockSize() != 0 {
+		return nil, errAesDataSizeMustBeMultipleOfBlockSize
+	}
+	dst := make([]byte, len(src))
+	s.blockMode.CryptBlocks(dst, src)
+	return dst, nil
+}
+
+// Pad and encrypt the last part of the message, then restart from the iv for the next message.
+// The src can be of any size.
+//
+// The result will not share the array of src.
+func (s *AesBlockModeEncryptStream) EncryptFinal(src []byte) ([]byte, error) {
+	if s.encrypter.err != nil {
+		return nil, s.encrypter.err
+	}
+	buf := s.encrypter.padding.Pad(src)
+	if len(buf)%AesBlockSize != 0 { // Such as NoPadding with unaligned src.
+		return nil, errAesDataSizeMustBeMultipleOfBlockSize
+	}
+	s.blockMode.CryptBlocks(buf, buf)
+	s.blockMode = s.encrypter.newBlockMode(s.encrypter.block, s.encrypter.iv)
+	return buf, nil
+}
+
+// Drop the chaining state and restart from the iv.
+// If iv is nil, restart from the current iv. Otherwise the iv must be 16 bytes.
+func (s *AesBlockModeEncryptStream) Reset(iv []byte) error {
+	if iv != nil {
+		if err := s.encrypter.Reset(iv); err != nil {
+			return err
+		}
+	}
+	if s.encrypter.err != nil {
+		return s.encrypter.err
+	}
+	s.blockMode = s.encrypter.newBlockMode(s.encrypter.block, s.encrypter.iv)
+	return nil
+}
+
+// Each call of Decrypt is an independent message which starts from the iv.
+// Use NewStream to decrypt a message part by part.
+//
+// It may has an error, call HasError to see it.
+type AesBlockModeDecrypter struct {
+	block        cipher.Block
+	iv           []byte
+	newBlockMode newBlockModeFunc
+	padding      Padding
+	err          error
+}
+
+func newAesBlockModeDecrypter(block cipher.Block, iv []byte, newBlockMode newBlockModeFunc, padding Padding, err error) AesBlockModeDecrypter {
 	return AesBlockModeDecrypter{
-		blockMode: blockMode,
-		padding:   padding,
-		err:       err,
+		block:        block,
+		iv:           append([]byte(nil), iv...),
+		newBlockMode: newBlockMode,
+		padding:      padding,
+		err:          err,
 	}
 }
 
@@ -156,29 +297,140 @@ func (d AesBlockModeDecrypter) HasError() (error, bool) {
 	return d.err, d.err != nil
 }
 
+// Decrypt src as a whole message from the iv.
+//
 // The result will not share the array of src.
 func (d AesBlockModeDecrypter) Decrypt(src []byte) ([]byte, error) {
 	if d.err != nil {
 		return nil, d.err
 	}
-	if len(src)%d.blockMode.BlockSize() != 0 {
+	return d.decrypt(d.iv, src)
+}
+
+// Decrypt src as a whole message from the iv param instead of the configured one.
+// The iv must be 16 bytes.
+//
+// The result will not share the array of src.
+func (d AesBlockModeDecrypter) DecryptWithIv(iv, src []byte) ([]byte, error) {
+	if d.err != nil {
+		return nil, d.err
+	}
+	if err := checkIv(iv); err != nil {
+		return nil, err
+	}
+	return d.decrypt(iv, src)
+}
+
+func (d AesBlockModeDecrypter) decrypt(iv, src []byte) ([]byte, error) {
+	if len(src)%d.block.BlockSize() != 0 {
 		return nil, errAesDataSizeMustBeMultipleOfBlockSize
 	}
 	dst := make([]byte, len(src))
-	d.blockMode.CryptBlocks(dst, src)
+	d.newBlockMode(d.block, iv).CryptBlocks(dst, src)
 	return d.padding.Unpad(dst)
 }
 
+// Replace the configured iv, which is used by the later Decrypt and NewStream.
+// The iv must be 16 bytes.
+func (d *AesBlockModeDecrypter) Reset(iv []byte) error {
+	if d.err != nil {
+		return d.err
+	}
+	if err := checkIv(iv); err != nil {
+		return err
+	}
+	d.iv = append([]byte(nil), iv...)
+	return nil
+}
+
+// Create a stream which decrypts a message part by part from the configured iv.
+// If d has error, the stream has the same error.
+func (d AesBlockModeDecrypter) NewStream() *AesBlockModeDecryptStream {
+	s := &AesBlockModeDecryptStream{
+		decrypter: d,
+	}
+	if d.err == nil {
+		s.blockMode = d.newBlockMode(d.block, d.iv)
+	}
+	return s
+}
+
+// It keeps the chaining state between calls, so that the parts of a message are decrypted
+// as if they were concatenated. Use it by pointer.
+//
+// It may has an error, call HasError to see it.
+type AesBlockModeDecryptStream struct {
+	decrypter AesBlockModeDecrypter
+	blockMode cipher.BlockMode
+}
+
+func (s *AesBlockModeDecryptStream) HasError() (error, bool) {
+	return s.decrypter.HasError()
+}
+
+// Decrypt the next part of the message without unpadding.
+// The size of src must be multiple of block size.
+// Do not pass the last part here, pass it to DecryptFinal.
+//
+// The result will not share the array of src.
+func (s *AesBlockModeDecryptStream) DecryptBlocks(src []byte) ([]byte, error) {
+	if s.decrypter.err != nil {
+		return nil, s.decrypter.err
+	}
+	if len(src)%s.blockMode.BlockSize() != 0 {
+		return nil, errAesDataSizeMustBeMultipleOfBlockSize
+	}
+	dst := make([]byte, len(src))
+	s.blockMode.CryptBlocks(dst, src)
+	return dst, nil
+}
+
+// Decrypt and unpad the last part of the message, then restart from the iv for the next message.
+// The size of src must be multiple of block size.
+//
+// The result will not share the array of src.
+func (s *AesBlockModeDecryptStream) DecryptFinal(src []byte) ([]byte, error) {
+	dst, err := s.DecryptBlocks(src)
+	if err != nil {
+		return nil, err
+	}
+	s.blockMode = s.decrypter.newBlockMode(s.decrypter.block, s.decrypter.iv)
+	return s.decrypter.padding.Unpad(dst)
+}
+
+// Drop the chaining state and restart from the iv.
+// If iv is nil, restart from the current iv. Otherwise the iv must be 16 bytes.
+func (s *AesBlockModeDecryptStream) Reset(iv []byte) error {
+	if iv != nil {
+		if err := s.decrypter.Reset(iv); err != nil {
+			return err
+		}
+	}
+	if s.decrypter.err != nil {
+		return s.decrypter.err
+	}
+	s.blockMode = s.decrypter.newBlockMode(s.decrypter.block, s.decrypter.iv)
+	return nil
+}
+
+// Each call of Crypt is an independent message which starts from the iv,
+// so the same input is always crypted to the same output with the same iv.
+// Use NewStream to crypt a message part by part.
+//
 // It may has an error, call HasError to see it.
 type AesStream struct {
-	stream cipher.Stream
-	err    error
+	block     cipher.Block
+	iv        []byte
+	newStream newStreamFunc
+	err       error
 }
 
-func newAesStream(stream cipher.Stream, err error) AesStream {
+func newAesStream(block cipher.Block, iv []byte, newStream newStreamFunc, err error) AesStream {
 	return AesStream{
-		stream: stream,
-		err:    err,
+		block:     block,
+		iv:        append([]byte(nil), iv...),
+		newStream: newStream,
+		err:       err,
 	}
 }
 
@@ -186,12 +438,97 @@ func (s AesStream) HasError() (error, bool) {
 	return s.err, s.err != nil
 }
 
+// Crypt src as a whole message from the iv.
+//
 // The result will not share the array of src.
 func (s AesStream) Crypt(src []byte) ([]byte, error) {
 	if s.err != nil {
 		return nil, s.err
 	}
+	return s.crypt(s.iv, src), nil
+}
+
+// Crypt src as a whole message from the iv param instead of the configured one.
+// The iv must be 16 bytes.
+//
+// The result will not share the array of src.
+func (s AesStream) CryptWithIv(iv, src []byte) ([]byte, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	if err := checkIv(iv); err != nil {
+		return nil, err
+	}
+	return s.crypt(iv, src), nil
+}
+
+func (s AesStream) crypt(iv, src []byte) []byte {
 	dst := make([]byte, len(src))
-	s.stream.XORKeyStream(dst, src)
+	s.newStream(s.block, iv).XORKeyStream(dst, src)
+	return dst
+}
+
+// Replace the configured iv, which is used by the later Crypt and NewStream.
+// The iv must be 16 bytes.
+func (s *AesStream) Reset(iv []byte) error {
+	if s.err != nil {
+		return s.err
+	}
+	if err := checkIv(iv); err != nil {
+		return err
+	}
+	s.iv = append([]byte(nil), iv...)
+	return nil
+}
+
+// Create a key stream which crypts a message part by part from the configured iv.
+// If s has error, the key stream has the same error.
+func (s AesStream) NewStream() *AesKeyStream {
+	k := &AesKeyStream{
+		aesStream: s,
+	}
+	if s.err == nil {
+		k.stream = s.newStream(s.block, s.iv)
+	}
+	return k
+}
+
+// It keeps the key stream position between calls, so that the parts of a message are crypted
+// as if they were concatenated. Use it by pointer.
+//
+// It may has an error, call HasError to see it.
+type AesKeyStream struct {
+	aesStream AesStream
+	stream    cipher.Stream
+}
+
+func (k *AesKeyStream) HasError() (error, bool) {
+	return k.aesStream.HasError()
+}
+
+// Crypt the next part of the message. The src can be of any size.
+//
+// The result will not share the array of src.
+func (k *AesKeyStream) Crypt(src []byte) ([]byte, error) {
+	if k.aesStream.err != nil {
+		return nil, k.aesStream.err
+	}
+	dst := make([]byte, len(src))
+	k.stream.XORKeyStream(dst, src)
 	return dst, nil
 }
+
+// Drop the key stream position and restart from the iv.
+// If iv is nil, restart from the current iv. Otherwise the iv must be 16 bytes.
+func (k *AesKeyStream) Reset(iv []byte) error {
+	if iv != nil {
+		if err := k.aesStream.Reset(iv); err != nil {
+			return err
+		}
+	}
+	if k.aesStream.err != nil {
+		return k.aesStream.err
+	}
+	k.stream = k.aesStream.newStream(k.aesStream.block, k.aesStream.iv)
+	return nil
+}
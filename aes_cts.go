@@ -0,0 +1,182 @@
+package crypt
+
+import (
+	"crypto/cipher"
+	"errors"
+)
+
+// The variant of ciphertext stealing, see the addendum of NIST SP 800-38A.
+type AesCbcCtsVariant int
+
+const (
+	// The partial last block is placed before the full last block.
+	// It is the same as CBC if the size is multiple of block size.
+	AesCbcCs1 AesCbcCtsVariant = 1
+	// The last two blocks are swapped if the size is not multiple of block size.
+	// It is the same as CBC if the size is multiple of block size.
+	AesCbcCs2 AesCbcCtsVariant = 2
+	// The last two blocks are always swapped, if there are at least two blocks.
+	AesCbcCs3 AesCbcCtsVariant = 3
+	// The CTS of Kerberos in RFC 3962, which is the same as AesCbcCs3.
+	AesCbcCtsKerberos = AesCbcCs3
+)
+
+var (
+	errAesCbcCtsVariantIllegal = errors.New("aes cbc cts variant illegal")
+	errAesCbcCtsDataTooShort   = errors.New("aes cbc cts data shorter than block size")
+)
+
+// AES-CBC with ciphertext stealing. The ciphertext is of the same size as the plaintext,
+// and the plaintext can be of any size not less than block size. No padding is needed.
+// Each call of Encrypt or Decrypt is an independent message which starts from the iv.
+//
+// It may has an error, call HasError to see it.
+type AesCbcCts struct {
+	block   cipher.Block
+	iv      []byte
+	variant AesCbcCtsVariant
+	err     error
+}
+
+// The key must be either 16, 24, or 32 bytes to select AES-128, AES-192, or AES-256.
+// The iv must be 16 bytes.
+//
+// variant:
+// AesCbcCs1, AesCbcCs2, AesCbcCs3 or AesCbcCtsKerberos.
+//
+// Can call HasError to see if it has an error.
+func NewAesCbcCts(key, iv []byte, variant AesCbcCtsVariant) AesCbcCts {
+	if variant < AesCbcCs1 || variant > AesCbcCs3 {
+		return AesCbcCts{err: errAesCbcCtsVariantIllegal}
+	}
+	block, err := checkKeyIv(key, iv)
+	if err != nil {
+		return AesCbcCts{err: err}
+	}
+	return AesCbcCts{
+		block:   block,
+		iv:      append([]byte(nil), iv...),
+		variant: variant,
+	}
+}
+
+func (c AesCbcCts) HasError() (error, bool) {
+	return c.err, c.err != nil
+}
+
+// Encrypt src as a whole message from the iv.
+// The size of src must not be less than block size.
+//
+// The result will not share the array of src.
+func (c AesCbcCts) Encrypt(src []byte) ([]byte, error) {
+	return c.EncryptWithIv(c.iv, src)
+}
+
+// Encrypt src as a whole message from the iv param instead of the configured one.
+// The iv must be 16 bytes.
+//
+// The result will not share the array of src.
+func (c AesCbcCts) EncryptWithIv(iv, src []byte) ([]byte, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	if err := checkIv(iv); err != nil {
+		return nil, err
+	}
+	if len(src) < AesBlockSize {
+		return nil, errAesCbcCtsDataTooShort
+	}
+	n, d := aesCbcCtsBlocks(len(src))
+	// Encrypt by CBC with the last block padded by zeros, then steal the ciphertext of the second last block.
+	buf := make([]byte, n*AesBlockSize)
+	copy(buf, src)
+	cipher.NewCBCEncrypter(c.block, iv).CryptBlocks(buf, buf)
+	if n == 1 {
+		return buf, nil
+	}
+	second := buf[(n-2)*AesBlockSize : (n-1)*AesBlockSize]
+	last := buf[(n-1)*AesBlockSize:]
+	dst := make([]byte, 0, len(src))
+	dst = append(dst, buf[:(n-2)*AesBlockSize]...)
+	if c.swapped(d) {
+		dst = append(dst, last...)
+		return append(dst, second[:d]...), nil
+	}
+	dst = append(dst, second[:d]...)
+	return append(dst, last...), nil
+}
+
+// Decrypt src as a whole message from the iv.
+// The size of src must not be less than block size.
+//
+// The result will not share the array of src.
+func (c AesCbcCts) Decrypt(src []byte) ([]byte, error) {
+	return c.DecryptWithIv(c.iv, src)
+}
+
+// Decrypt src as a whole message from the iv param instead of the configured one.
+// The iv must be 16 bytes.
+//
+// The result will not share the array of src.
+func (c AesCbcCts) DecryptWithIv(iv, src []byte) ([]byte, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	if err := checkIv(iv); err != nil {
+		return nil, err
+	}
+	if len(src) < AesBlockSize {
+		return nil, errAesCbcCtsDataTooShort
+	}
+	n, d := aesCbcCtsBlocks(len(src))
+	dst := make([]byte, len(src))
+	if n == 1 {
+		cipher.NewCBCDecrypter(c.block, iv).CryptBlocks(dst, src)
+		return dst, nil
+	}
+	// Restore the ciphertext of CBC: C1 ... Cn-2 | Cn-1 | Cn.
+	tail := src[(n-2)*AesBlockSize:]
+	var secondPart, last []byte
+	if c.swapped(d) {
+		last, secondPart = tail[:AesBlockSize], tail[AesBlockSize:]
+	} else {
+		secondPart, last = tail[:d], tail[d:]
+	}
+	// D(Cn) = Cn-1 xor (Pn | zeros), so the stolen bytes of Cn-1 are the tail of D(Cn).
+	z := make([]byte, AesBlockSize)
+	c.block.Decrypt(z, last)
+	buf := make([]byte, (n-1)*AesBlockSize)
+	copy(buf, src[:(n-2)*AesBlockSize])
+	second := buf[(n-2)*AesBlockSize:]
+	copy(second, secondPart)
+	copy(second[d:], z[d:])
+	for i := 0; i < d; i++ {
+		dst[(n-1)*AesBlockSize+i] = z[i] ^ secondPart[i]
+	}
+	cipher.NewCBCDecrypter(c.block, iv).CryptBlocks(dst[:(n-1)*AesBlockSize], buf)
+	return dst, nil
+}
+
+// Replace the configured iv, which is used by the later Encrypt and Decrypt.
+// The iv must be 16 bytes.
+func (c *AesCbcCts) Reset(iv []byte) error {
+	if c.err != nil {
+		return c.err
+	}
+	if err := checkIv(iv); err != nil {
+		return err
+	}
+	c.iv = append([]byte(nil), iv...)
+	return nil
+}
+
+// Whether the last two blocks are swapped, d is the size of the last block.
+func (c AesCbcCts) swapped(d int) bool {
+	return c.variant == AesCbcCs3 || (c.variant == AesCbcCs2 && d != AesBlockSize)
+}
+
+// Return the number of blocks and the size of the last block, which is in [1, AesBlockSize].
+func aesCbcCtsBlocks(size int) (int, int) {
+	n := (size + AesBlockSize - 1) / AesBlockSize
+	return n, size - (n-1)*AesBlockSize
+}
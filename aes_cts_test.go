@@ -0,0 +1,80 @@
+package crypt
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+func TestAesCbcCts(t *testing.T) {
+	// The key, iv and plaintext are of RFC 3962, whose vectors are the same as AesCbcCs3.
+	// The vectors of AesCbcCs1 and AesCbcCs2 are generated by OpenSSL AES-128-CBC-CTS.
+	key := []byte("chicken teriyaki")
+	iv := make([]byte, AesIvSize)
+	plaintext := []byte("I would like the General Gau's Chicken, please, and wonton soup.")
+	vectors := []struct {
+		variant AesCbcCtsVariant
+		size    int
+		enc     string
+	}{
+		{AesCbcCs1, 17, "97c6353568f2bf8cb4d8a580362da7ff7f"},
+		{AesCbcCs1, 31, "97687268d6ecccc0c07b25e25ecfe5fc00783e0efdb2c1d445d4c8eff7ed22"},
+		{AesCbcCs1, 32, "97687268d6ecccc0c07b25e25ecfe58439312523a78662d5be7fcbcc98ebf5a8"},
+		{AesCbcCs1, 47, "97687268d6ecccc0c07b25e25ecfe58439312523a78662d5be7fcbcc98ebf5b3fffd940c16a18c1b5549d2f838029e"},
+		{AesCbcCs1, 48, "97687268d6ecccc0c07b25e25ecfe58439312523a78662d5be7fcbcc98ebf5a89dad8bbb96c4cdc03bc103e1a194bbd8"},
+		{AesCbcCs1, 64, "97687268d6ecccc0c07b25e25ecfe58439312523a78662d5be7fcbcc98ebf5a89dad8bbb96c4cdc03bc103e1a194bbd84807efe836ee89a526730dbc2f7bc840"},
+		{AesCbcCs2, 17, "c6353568f2bf8cb4d8a580362da7ff7f97"},
+		{AesCbcCs2, 31, "fc00783e0efdb2c1d445d4c8eff7ed2297687268d6ecccc0c07b25e25ecfe5"},
+		{AesCbcCs2, 32, "97687268d6ecccc0c07b25e25ecfe58439312523a78662d5be7fcbcc98ebf5a8"},
+		{AesCbcCs2, 47, "97687268d6ecccc0c07b25e25ecfe584b3fffd940c16a18c1b5549d2f838029e39312523a78662d5be7fcbcc98ebf5"},
+		{AesCbcCs2, 48, "97687268d6ecccc0c07b25e25ecfe58439312523a78662d5be7fcbcc98ebf5a89dad8bbb96c4cdc03bc103e1a194bbd8"},
+		{AesCbcCs2, 64, "97687268d6ecccc0c07b25e25ecfe58439312523a78662d5be7fcbcc98ebf5a89dad8bbb96c4cdc03bc103e1a194bbd84807efe836ee89a526730dbc2f7bc840"},
+		{AesCbcCs3, 17, "c6353568f2bf8cb4d8a580362da7ff7f97"},
+		{AesCbcCs3, 31, "fc00783e0efdb2c1d445d4c8eff7ed2297687268d6ecccc0c07b25e25ecfe5"},
+		{AesCbcCs3, 32, "39312523a78662d5be7fcbcc98ebf5a897687268d6ecccc0c07b25e25ecfe584"},
+		{AesCbcCs3, 47, "97687268d6ecccc0c07b25e25ecfe584b3fffd940c16a18c1b5549d2f838029e39312523a78662d5be7fcbcc98ebf5"},
+		{AesCbcCs3, 48, "97687268d6ecccc0c07b25e25ecfe5849dad8bbb96c4cdc03bc103e1a194bbd839312523a78662d5be7fcbcc98ebf5a8"},
+		{AesCbcCs3, 64, "97687268d6ecccc0c07b25e25ecfe58439312523a78662d5be7fcbcc98ebf5a84807efe836ee89a526730dbc2f7bc8409dad8bbb96c4cdc03bc103e1a194bbd8"},
+	}
+	for _, v := range vectors {
+		cts := NewAesCbcCts(key, iv, v.variant)
+		enc, err := cts.Encrypt(plaintext[:v.size])
+		if err != nil {
+			t.Fatal("encrypt err:", v.variant, v.size, err)
+		}
+		if hex.EncodeToString(enc) != v.enc {
+			t.Error("encrypt wrong result:", v.variant, v.size, hex.EncodeToString(enc))
+		}
+		dec, err := cts.Decrypt(enc)
+		if err != nil || !bytes.Equal(dec, plaintext[:v.size]) {
+			t.Error("decrypt err:", v.variant, v.size, err)
+		}
+	}
+
+	// A single block is the same as CBC.
+	cbc, _ := NewAesCbcEncrypter(key, iv, NewNoPadding(AesBlockSize)).Encrypt(plaintext[:AesBlockSize])
+	for _, variant := range []AesCbcCtsVariant{AesCbcCs1, AesCbcCs2, AesCbcCs3} {
+		cts := NewAesCbcCts(key, iv, variant)
+		if enc, _ := cts.Encrypt(plaintext[:AesBlockSize]); !bytes.Equal(enc, cbc) {
+			t.Error("encrypt a single block is wrong:", variant)
+		}
+		if dec, _ := cts.Decrypt(cbc); !bytes.Equal(dec, plaintext[:AesBlockSize]) {
+			t.Error("decrypt a single block is wrong:", variant)
+		}
+		for size := AesBlockSize; size <= len(plaintext); size++ {
+			enc, _ := cts.EncryptWithIv(key, plaintext[:size])
+			if dec, err := cts.DecryptWithIv(key, enc); err != nil || !bytes.Equal(dec, plaintext[:size]) {
+				t.Error("decrypt with iv err:", variant, size, err)
+			}
+		}
+		if _, err := cts.Encrypt(plaintext[:AesBlockSize-1]); err != errAesCbcCtsDataTooShort {
+			t.Error("encrypt short data err:", variant, err)
+		}
+		if _, err := cts.Decrypt(plaintext[:AesBlockSize-1]); err != errAesCbcCtsDataTooShort {
+			t.Error("decrypt short data err:", variant, err)
+		}
+	}
+	if _, ok := NewAesCbcCts(key, iv, 4).HasError(); !ok {
+		t.Error("illegal variant should have error")
+	}
+}
@@ -0,0 +1,131 @@
+package crypt
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"errors"
+)
+
+var errAesEcbNotAcknowledged = errors.New("aes ecb is insecure and must be acknowledged by AesEcbInsecureLegacy()")
+
+// It acknowledges that ECB is insecure. Only the result of AesEcbInsecureLegacy is accepted.
+type AesEcbAcknowledgement struct {
+	insecureLegacy bool
+}
+
+// Pass the result to NewAesEcbEncrypter and NewAesEcbDecrypter to acknowledge that ECB is insecure:
+// the same plaintext block is always encrypted to the same ciphertext block, which leaks the patterns of data.
+// Only use ECB to interoperate with the legacy systems, such as Java "AES/ECB/PKCS5Padding".
+// Java's PKCS5Padding of AES is padding to 16 bytes, which is NewPkcs7Padding(AesBlockSize) but not NewPkcs5Padding().
+func AesEcbInsecureLegacy() AesEcbAcknowledgement {
+	return AesEcbAcknowledgement{insecureLegacy: true}
+}
+
+// There is no iv in ECB, so it has no method about iv, and each block is encrypted independently.
+//
+// It may has an error, call HasError to see it.
+type AesEcbEncrypter struct {
+	encrypter AesBlockModeEncrypter
+}
+
+// The key must be either 16, 24, or 32 bytes to select AES-128, AES-192, or AES-256.
+// The block size of padding must be 16 bytes, such as NewPkcs7Padding(AesBlockSize).
+// The ack must be the result of AesEcbInsecureLegacy.
+//
+// Can call HasError to see if it has an error.
+func NewAesEcbEncrypter(key []byte, padding Padding, ack AesEcbAcknowledgement) AesEcbEncrypter {
+	block, err := checkKeyEcb(key, padding, ack)
+	if err != nil {
+		return AesEcbEncrypter{encrypter: newAesBlockModeEncrypter(nil, nil, nil, nil, err)}
+	}
+	return AesEcbEncrypter{encrypter: newAesBlockModeEncrypter(block, nil, newEcbEncrypter, padding, nil)}
+}
+
+func (e AesEcbEncrypter) HasError() (error, bool) {
+	return e.encrypter.HasError()
+}
+
+// Pad and encrypt src.
+//
+// The result will not share the array of src.
+func (e AesEcbEncrypter) Encrypt(src []byte) ([]byte, error) {
+	return e.encrypter.Encrypt(src)
+}
+
+// There is no iv in ECB, so it has no method about iv, and each block is decrypted independently.
+//
+// It may has an error, call HasError to see it.
+type AesEcbDecrypter struct {
+	decrypter AesBlockModeDecrypter
+}
+
+// The key must be either 16, 24, or 32 bytes to select AES-128, AES-192, or AES-256.
+// The block size of padding must be 16 bytes, such as NewPkcs7Padding(AesBlockSize).
+// The ack must be the result of AesEcbInsecureLegacy.
+//
+// Can call HasError to see if it has an error.
+func NewAesEcbDecrypter(key []byte, padding Padding, ack AesEcbAcknowledgement) AesEcbDecrypter {
+	block, err := checkKeyEcb(key, padding, ack)
+	if err != nil {
+		return AesEcbDecrypter{decrypter: newAesBlockModeDecrypter(nil, nil, nil, nil, err)}
+	}
+	return AesEcbDecrypter{decrypter: newAesBlockModeDecrypter(block, nil, newEcbDecrypter, padding, nil)}
+}
+
+func (d AesEcbDecrypter) HasError() (error, bool) {
+	return d.decrypter.HasError()
+}
+
+// Decrypt and unpad src.
+//
+// The result will not share the array of src.
+func (d AesEcbDecrypter) Decrypt(src []byte) ([]byte, error) {
+	return d.decrypter.Decrypt(src)
+}
+
+func checkKeyEcb(key []byte, padding Padding, ack AesEcbAcknowledgement) (cipher.Block, error) {
+	if !ack.insecureLegacy {
+		return nil, errAesEcbNotAcknowledged
+	}
+	if padding.BlockSize() != AesBlockSize {
+		return nil, errAesPaddingBlockSizeMustBeAesBlockSize
+	}
+	return aes.NewCipher(key)
+}
+
+// It implements cipher.BlockMode of ECB, each block is crypted independently.
+type ecb struct {
+	block   cipher.Block
+	encrypt bool
+}
+
+// The iv is ignored.
+func newEcbEncrypter(block cipher.Block, iv []byte) cipher.BlockMode {
+	return ecb{block: block, encrypt: true}
+}
+
+// The iv is ignored.
+func newEcbDecrypter(block cipher.Block, iv []byte) cipher.BlockMode {
+	return ecb{block: block, encrypt: false}
+}
+
+func (e ecb) BlockSize() int {
+	return e.block.BlockSize()
+}
+
+func (e ecb) CryptBlocks(dst, src []byte) {
+	size := e.block.BlockSize()
+	if len(src)%size != 0 {
+		panic("crypt: input not full blocks")
+	}
+	if len(dst) < len(src) {
+		panic("crypt: output smaller than input")
+	}
+	for i := 0; i < len(src); i += size {
+		if e.encrypt {
+			e.block.Encrypt(dst[i:i+size], src[i:i+size])
+		} else {
+			e.block.Decrypt(dst[i:i+size], src[i:i+size])
+		}
+	}
+}
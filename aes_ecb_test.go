@@ -0,0 +1,105 @@
+package crypt
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+func TestAesEcb(t *testing.T) {
+	// The vectors are of F.1 of NIST SP 800-38A.
+	plaintext, _ := hex.DecodeString("6bc1bee22e409f96e93d7e117393172aae2d8a571e03ac9c9eb76fac45af8e51" +
+		"30c81c46a35ce411e5fbc1191a0a52eff69f2445df4f9b17ad2b417be66c3710")
+	vectors := []struct {
+		key string
+		enc string
+	}{
+		{
+			key: "2b7e151628aed2a6abf7158809cf4f3c",
+			enc: "3ad77bb40d7a3660a89ecaf32466ef97f5d3d58503b9699de785895a96fdbaaf" +
+				"43b1cd7f598ece23881b00e3ed0306887b0c785e27e8ad3f8223207104725dd4",
+		},
+		{
+			key: "8e73b0f7da0e6452c810f32b809079e562f8ead2522c6b7b",
+			enc: "bd334f1d6e45f25ff712a214571fa5cc974104846d0ad3ad7734ecb3ecee4eef" +
+				"ef7afd2270e2e60adce0ba2face6444e9a4b41ba738d6c72fb16691603c18e0e",
+		},
+		{
+			key: "603deb1015ca71be2b73aef0857d77811f352c073b6108d72d9810a30914dff4",
+			enc: "f3eed1bdb5d2a03c064b5a7e3db181f8591ccb10d410ed26dc5ba74a31362870" +
+				"b6ed21b99ca6f4f9f153e7b1beafed1d23304b7a39f9f3ff067d8d8f9e24ecc7",
+		},
+	}
+	padding := NewNoPadding(AesBlockSize)
+	for _, v := range vectors {
+		key, _ := hex.DecodeString(v.key)
+		enc, err := NewAesEcbEncrypter(key, padding, AesEcbInsecureLegacy()).Encrypt(plaintext)
+		if err != nil {
+			t.Fatal("encrypt err:", err)
+		}
+		if hex.EncodeToString(enc) != v.enc {
+			t.Error("encrypt wrong result:", len(key), hex.EncodeToString(enc))
+		}
+		dec, err := NewAesEcbDecrypter(key, padding, AesEcbInsecureLegacy()).Decrypt(enc)
+		if err != nil || !bytes.Equal(dec, plaintext) {
+			t.Error("decrypt err:", len(key), err)
+		}
+	}
+}
+
+func TestAesEcbWithPadding(t *testing.T) {
+	// The vector is generated by openssl enc -aes-128-ecb, which is the same as Java "AES/ECB/PKCS5Padding".
+	key := []byte("1234567890123456")
+	input := []byte("I love this girl! Does she?")
+	encrypter := NewAesEcbEncrypter(key, NewPkcs7Padding(AesBlockSize), AesEcbInsecureLegacy())
+	decrypter := NewAesEcbDecrypter(key, NewPkcs7Padding(AesBlockSize), AesEcbInsecureLegacy())
+	enc, err := encrypter.Encrypt(input)
+	if err != nil {
+		t.Fatal("encrypt err:", err)
+	}
+	if hex.EncodeToString(enc) != "7916db62c2d25cbf15e96f3a7173a7485ad7c2890179a6ec4845f71919b9a185" {
+		t.Error("encrypt wrong result:", hex.EncodeToString(enc))
+	}
+	dec, err := decrypter.Decrypt(enc)
+	if err != nil || !bytes.Equal(dec, input) {
+		t.Error("decrypt err:", err)
+	}
+
+	// Each block is crypted independently.
+	if enc2, _ := encrypter.Encrypt(append(input[:AesBlockSize:AesBlockSize], input...)); !bytes.Equal(enc2[AesBlockSize:], enc) {
+		t.Error("the blocks are not crypted independently")
+	}
+	if _, err := decrypter.Decrypt(enc[1:]); err == nil {
+		t.Error("decrypt unaligned data should have error")
+	}
+
+	if _, ok := NewAesEcbEncrypter(key, NewPkcs7Padding(AesBlockSize), AesEcbAcknowledgement{}).HasError(); !ok {
+		t.Error("encrypter without acknowledgement should have error")
+	}
+	if _, ok := NewAesEcbDecrypter(key, NewPkcs7Padding(AesBlockSize), AesEcbAcknowledgement{}).HasError(); !ok {
+		t.Error("decrypter without acknowledgement should have error")
+	}
+}
+
+func TestAesEcbJavaPkcs5Padding(t *testing.T) {
+	// Java "AES/ECB/PKCS5Padding" pads to 16 bytes, so it is NewPkcs7Padding(AesBlockSize).
+	key := []byte("1234567890123456")
+	input := []byte("I love this girl! Does she?")
+	padding := NewPkcs7Padding(AesBlockSize)
+	enc, err := NewAesEcbEncrypter(key, padding, AesEcbInsecureLegacy()).Encrypt(input)
+	if err != nil {
+		t.Fatal("encrypt err:", err)
+	}
+	if hex.EncodeToString(enc) != "7916db62c2d25cbf15e96f3a7173a7485ad7c2890179a6ec4845f71919b9a185" {
+		t.Error("encrypt wrong result:", hex.EncodeToString(enc))
+	}
+	dec, err := NewAesEcbDecrypter(key, padding, AesEcbInsecureLegacy()).Decrypt(enc)
+	if err != nil || !bytes.Equal(dec, input) {
+		t.Error("decrypt err:", err)
+	}
+
+	// NewPkcs5Padding pads to 8 bytes as DES, which is not for AES.
+	if err, _ := NewAesEcbEncrypter(key, NewPkcs5Padding(), AesEcbInsecureLegacy()).HasError(); err != errAesPaddingBlockSizeMustBeAesBlockSize {
+		t.Error("pkcs5 padding err:", err)
+	}
+}
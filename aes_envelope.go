@@ -0,0 +1,202 @@
+package crypt
+
+import (
+	"crypto/aes"
+	"crypto/rand"
+	"errors"
+	"io"
+	"strconv"
+)
+
+// The algorithm id stored in the header of an AES envelope.
+type AesEnvelopeAlgorithm byte
+
+const (
+	AesEnvelopeCbcPkcs7 AesEnvelopeAlgorithm = 1 // AES-CBC with PKCS#7 padding.
+	AesEnvelopeCfb      AesEnvelopeAlgorithm = 2 // AES-CFB.
+	AesEnvelopeOfb      AesEnvelopeAlgorithm = 3 // AES-OFB.
+	AesEnvelopeCtr      AesEnvelopeAlgorithm = 4 // AES-CTR.
+	AesEnvelopeGcm      AesEnvelopeAlgorithm = 5 // AES-GCM with standard nonce size and tag size.
+	AesEnvelopeSiv      AesEnvelopeAlgorithm = 6 // AES-SIV with a 16 bytes nonce as the last additional data.
+	AesEnvelopeGcmSiv   AesEnvelopeAlgorithm = 7 // AES-GCM-SIV.
+)
+
+const (
+	AesEnvelopeVersion1   = 1                   // The current envelope version.
+	AesEnvelopeHeaderSize = 2                   // The version byte and the algorithm byte.
+	aesEnvelopeVersion    = AesEnvelopeVersion1 // The version written by Seal.
+)
+
+var (
+	errAesEnvelopeAlgorithmUnknown  = errors.New("aes envelope algorithm unknown")
+	errAesEnvelopeAlgorithmMismatch = errors.New("aes envelope algorithm mismatch")
+	errAesEnvelopeTooShort          = errors.New("aes envelope too short")
+)
+
+// It is returned by AesEnvelope.Open when the version of the envelope is unknown.
+type AesEnvelopeVersionError struct {
+	Version byte
+}
+
+func (e AesEnvelopeVersionError) Error() string {
+	return "aes envelope version " + strconv.Itoa(int(e.Version)) + " unknown"
+}
+
+// AesEnvelope encrypts each message with a fresh random iv or nonce,
+// and stores it in front of the ciphertext, so that the caller only handles the key and bytes.
+//
+// The layout of an envelope is:
+//
+//	version (1 byte) | algorithm (1 byte) | iv or nonce | ciphertext
+//
+// For AEAD algorithms, the version and algorithm bytes are authenticated as additional data.
+//
+// It may has an error, call HasError to see it.
+type AesEnvelope struct {
+	key       []byte
+	algorithm AesEnvelopeAlgorithm
+	err       error
+}
+
+// key:
+// The key must be either 16, 24, or 32 bytes to select AES-128, AES-192, or AES-256.
+// For AesEnvelopeSiv, it must be either 32, 48, or 64 bytes as NewAesSiv.
+// For AesEnvelopeGcmSiv, it must be either 16 or 32 bytes as NewAesGcmSiv.
+//
+// algorithm:
+// The algorithm used by Seal. Open only accepts envelopes of the same algorithm,
+// so that an authenticated envelope can not be downgraded to an unauthenticated one.
+//
+// Can call HasError to see if it has an error.
+func NewAesEnvelope(key []byte, algorithm AesEnvelopeAlgorithm) AesEnvelope {
+	if aesEnvelopeIvSize(algorithm) <= 0 {
+		return newAesEnvelope(nil, algorithm, errAesEnvelopeAlgorithmUnknown)
+	}
+	if err := checkAesEnvelopeKey(key, algorithm); err != nil {
+		return newAesEnvelope(nil, algorithm, err)
+	}
+	return newAesEnvelope(append([]byte(nil), key...), algorithm, nil)
+}
+
+func newAesEnvelope(key []byte, algorithm AesEnvelopeAlgorithm, err error) AesEnvelope {
+	return AesEnvelope{
+		key:       key,
+		algorithm: algorithm,
+		err:       err,
+	}
+}
+
+func (e AesEnvelope) HasError() (error, bool) {
+	return e.err, e.err != nil
+}
+
+// Each call generates a new random iv or nonce.
+//
+// The result will not share the array of plaintext.
+func (e AesEnvelope) Seal(plaintext []byte) ([]byte, error) {
+	if e.err != nil {
+		return nil, e.err
+	}
+	header := []byte{aesEnvelopeVersion, byte(e.algorithm)}
+	iv := make([]byte, aesEnvelopeIvSize(e.algorithm))
+	if _, err := io.ReadFull(rand.Reader, iv); err != nil {
+		return nil, err
+	}
+	enc, err := e.crypt(true, header, iv, plaintext)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]byte, 0, len(header)+len(iv)+len(enc))
+	result = append(result, header...)
+	result = append(result, iv...)
+	return append(result, enc...), nil
+}
+
+// If the version of envelope is unknown, return AesEnvelopeVersionError.
+// For AEAD algorithms, if the envelope has been tampered, return ErrAuthenticationFailed.
+//
+// The result will not share the array of envelope.
+func (e AesEnvelope) Open(envelope []byte) ([]byte, error) {
+	if e.err != nil {
+		return nil, e.err
+	}
+	if len(envelope) < AesEnvelopeHeaderSize {
+		return nil, errAesEnvelopeTooShort
+	}
+	if envelope[0] != AesEnvelopeVersion1 {
+		return nil, AesEnvelopeVersionError{Version: envelope[0]}
+	}
+	if AesEnvelopeAlgorithm(envelope[1]) != e.algorithm {
+		return nil, errAesEnvelopeAlgorithmMismatch
+	}
+	ivSize := aesEnvelopeIvSize(e.algorithm)
+	if len(envelope) < AesEnvelopeHeaderSize+ivSize {
+		return nil, errAesEnvelopeTooShort
+	}
+	header := envelope[:AesEnvelopeHeaderSize]
+	iv := envelope[AesEnvelopeHeaderSize:(AesEnvelopeHeaderSize + ivSize)]
+	return e.crypt(false, header, iv, envelope[(AesEnvelopeHeaderSize+ivSize):])
+}
+
+func (e AesEnvelope) crypt(encrypt bool, header, iv, src []byte) ([]byte, error) {
+	switch e.algorithm {
+	case AesEnvelopeCbcPkcs7:
+		if encrypt {
+			return NewAesCbcEncrypter(e.key, iv, NewPkcs7Padding(AesBlockSize)).Encrypt(src)
+		}
+		return NewAesCbcDecrypter(e.key, iv, NewPkcs7Padding(AesBlockSize)).Decrypt(src)
+	case AesEnvelopeCfb:
+		if encrypt {
+			return NewAesCfbEncrypter(e.key, iv).Crypt(src)
+		}
+		return NewAesCfbDecrypter(e.key, iv).Crypt(src)
+	case AesEnvelopeOfb:
+		return NewAesOfb(e.key, iv).Crypt(src)
+	case AesEnvelopeCtr:
+		return NewAesCtr(e.key, iv).Crypt(src)
+	case AesEnvelopeGcm:
+		if encrypt {
+			return NewAesGcm(e.key, 0, 0).Seal(iv, src, header)
+		}
+		return NewAesGcm(e.key, 0, 0).Open(iv, src, header)
+	case AesEnvelopeSiv:
+		if encrypt {
+			return NewAesSiv(e.key).Seal(src, header, iv)
+		}
+		return NewAesSiv(e.key).Open(src, header, iv)
+	case AesEnvelopeGcmSiv:
+		if encrypt {
+			return NewAesGcmSiv(e.key).Seal(iv, src, header)
+		}
+		return NewAesGcmSiv(e.key).Open(iv, src, header)
+	}
+	return nil, errAesEnvelopeAlgorithmUnknown
+}
+
+func checkAesEnvelopeKey(key []byte, algorithm AesEnvelopeAlgorithm) error {
+	switch algorithm {
+	case AesEnvelopeSiv:
+		err, _ := NewAesSiv(key).HasError()
+		return err
+	case AesEnvelopeGcmSiv:
+		err, _ := NewAesGcmSiv(key).HasError()
+		return err
+	}
+	_, err := aes.NewCipher(key)
+	return err
+}
+
+// Return 0 if the algorithm is unknown.
+func aesEnvelopeIvSize(algorithm AesEnvelopeAlgorithm) int {
+	switch algorithm {
+	case AesEnvelopeCbcPkcs7, AesEnvelopeCfb, AesEnvelopeOfb, AesEnvelopeCtr:
+		return AesIvSize
+	case AesEnvelopeGcm:
+		return AesGcmStandardNonceSize
+	case AesEnvelopeSiv:
+		return AesIvSize
+	case AesEnvelopeGcmSiv:
+		return AesGcmSivNonceSize
+	}
+	return 0
+}
@@ -0,0 +1,120 @@
+package crypt
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestAesEnvelope(t *testing.T) {
+	key := []byte("11112222333344445555666677778888")
+	data := []byte("I love this girl! Does she?")
+	algorithms := map[string]AesEnvelopeAlgorithm{
+		"cbc":     AesEnvelopeCbcPkcs7,
+		"cfb":     AesEnvelopeCfb,
+		"ofb":     AesEnvelopeOfb,
+		"ctr":     AesEnvelopeCtr,
+		"gcm":     AesEnvelopeGcm,
+		"siv":     AesEnvelopeSiv,
+		"gcm siv": AesEnvelopeGcmSiv,
+	}
+
+	for name, algorithm := range algorithms {
+		t.Run(name, func(t *testing.T) {
+			envelope := NewAesEnvelope(key, algorithm)
+			enc1, err := envelope.Seal(data)
+			if err != nil {
+				t.Fatal("seal:", err)
+			}
+			enc2, err := envelope.Seal(data)
+			if err != nil {
+				t.Fatal("seal:", err)
+			}
+			if bytes.Equal(enc1, enc2) {
+				t.Error("the same plaintext should be sealed with different iv")
+			}
+			if enc1[0] != AesEnvelopeVersion1 || AesEnvelopeAlgorithm(enc1[1]) != algorithm {
+				t.Error("envelope header is wrong:", enc1[:AesEnvelopeHeaderSize])
+			}
+			dec, err := envelope.Open(enc1)
+			if err != nil {
+				t.Error("open:", err)
+			}
+			if !bytes.Equal(dec, data) {
+				t.Error("open result is wrong:", dec)
+			}
+		})
+	}
+}
+
+func TestAesEnvelopeOpenIllegal(t *testing.T) {
+	key := []byte("11112222333344445555666677778888")
+	envelope := NewAesEnvelope(key, AesEnvelopeGcm)
+	enc, err := envelope.Seal([]byte("I love this girl! Does she?"))
+	if err != nil {
+		t.Fatal("seal:", err)
+	}
+
+	t.Run("version", func(t *testing.T) {
+		buf := append([]byte(nil), enc...)
+		buf[0] = 9
+		_, err := envelope.Open(buf)
+		var versionErr AesEnvelopeVersionError
+		if !errors.As(err, &versionErr) || versionErr.Version != 9 {
+			t.Error("open unknown version err:", err)
+		}
+	})
+	t.Run("algorithm", func(t *testing.T) {
+		if _, err := NewAesEnvelope(key, AesEnvelopeCtr).Open(enc); err == nil {
+			t.Error("open with mismatched algorithm should have error")
+		}
+	})
+	t.Run("tampered", func(t *testing.T) {
+		buf := append([]byte(nil), enc...)
+		buf[len(buf)-1] ^= 1
+		if _, err := envelope.Open(buf); err != ErrAuthenticationFailed {
+			t.Error("open tampered envelope err:", err)
+		}
+	})
+	t.Run("short", func(t *testing.T) {
+		if _, err := envelope.Open(enc[:AesEnvelopeHeaderSize+1]); err == nil {
+			t.Error("open short envelope should have error")
+		}
+	})
+}
+
+func TestAesEnvelopeMisuseResistantTampered(t *testing.T) {
+	key := []byte("11112222333344445555666677778888")
+	for name, algorithm := range map[string]AesEnvelopeAlgorithm{
+		"siv":     AesEnvelopeSiv,
+		"gcm siv": AesEnvelopeGcmSiv,
+	} {
+		t.Run(name, func(t *testing.T) {
+			envelope := NewAesEnvelope(key, algorithm)
+			enc, err := envelope.Seal([]byte("I love this girl! Does she?"))
+			if err != nil {
+				t.Fatal("seal:", err)
+			}
+			// Tamper the nonce, the ciphertext and the tag.
+			for _, i := range []int{AesEnvelopeHeaderSize, len(enc) / 2, len(enc) - 1} {
+				buf := append([]byte(nil), enc...)
+				buf[i] ^= 1
+				if _, err := envelope.Open(buf); err != ErrAuthenticationFailed {
+					t.Error("open tampered envelope err:", i, err)
+				}
+			}
+			// The header is authenticated as the additional data.
+			ivSize := aesEnvelopeIvSize(algorithm)
+			iv, body := enc[AesEnvelopeHeaderSize:AesEnvelopeHeaderSize+ivSize], enc[AesEnvelopeHeaderSize+ivSize:]
+			if _, err := envelope.crypt(false, []byte{AesEnvelopeVersion1, 0}, iv, body); err != ErrAuthenticationFailed {
+				t.Error("open with tampered header err:", err)
+			}
+		})
+	}
+	if _, ok := NewAesEnvelope(key[:Aes128KeySize], AesEnvelopeSiv).HasError(); !ok {
+		t.Error("aes siv envelope with 16 bytes key should have error")
+	}
+	if _, ok := NewAesEnvelope(key[:Aes192KeySize], AesEnvelopeGcmSiv).HasError(); !ok {
+		t.Error("aes gcm siv envelope with 24 bytes key should have error")
+	}
+}
@@ -0,0 +1,143 @@
+package crypt
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"errors"
+)
+
+const (
+	AesGcmStandardNonceSize = 12 // Size is 12 bytes.
+	AesGcmStandardTagSize   = 16 // Size is 16 bytes.
+	AesGcmMinTagSize        = 12 // Size is 12 bytes.
+)
+
+var (
+	errAesGcmNonceSizeIllegal            = errors.New("gcm nonce size illegal")
+	errAesGcmTagSizeIllegal              = errors.New("gcm tag size illegal")
+	errAesGcmNonceAndTagSizeNotStandard  = errors.New("gcm nonce size and tag size can not both be non-standard")
+	errAesGcmNonceLenMustBeNonceSize     = errors.New("nonce length must equal to nonce size")
+	errAesGcmCiphertextShorterThanTagLen = errors.New("ciphertext is shorter than tag")
+)
+
+// It may has an error, call HasError to see it.
+type AesGcm struct {
+	aead cipher.AEAD
+	err  error
+}
+
+// key:
+// The key must be either 16, 24, or 32 bytes to select AES-128, AES-192, or AES-256.
+//
+// nonceSize:
+// The byte size of nonce. If it is 0, AesGcmStandardNonceSize is used.
+// Use the standard size unless it must interoperate with an existing system.
+//
+// tagSize:
+// The byte size of authentication tag. If it is 0, AesGcmStandardTagSize is used.
+// It must be between AesGcmMinTagSize and AesGcmStandardTagSize.
+//
+// The nonce size and tag size can not both be non-standard.
+//
+// Can call HasError to see if it has an error.
+func NewAesGcm(key []byte, nonceSize, tagSize int) AesGcm {
+	if nonceSize == 0 {
+		nonceSize = AesGcmStandardNonceSize
+	}
+	if tagSize == 0 {
+		tagSize = AesGcmStandardTagSize
+	}
+	if nonceSize < 0 {
+		return newAesGcm(nil, errAesGcmNonceSizeIllegal)
+	}
+	if tagSize < AesGcmMinTagSize || tagSize > AesGcmStandardTagSize {
+		return newAesGcm(nil, errAesGcmTagSizeIllegal)
+	}
+	if nonceSize != AesGcmStandardNonceSize && tagSize != AesGcmStandardTagSize {
+		return newAesGcm(nil, errAesGcmNonceAndTagSizeNotStandard)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return newAesGcm(nil, err)
+	}
+	var aead cipher.AEAD
+	switch {
+	case nonceSize != AesGcmStandardNonceSize:
+		aead, err = cipher.NewGCMWithNonceSize(block, nonceSize)
+	case tagSize != AesGcmStandardTagSize:
+		aead, err = cipher.NewGCMWithTagSize(block, tagSize)
+	default:
+		aead, err = cipher.NewGCM(block)
+	}
+	if err != nil {
+		return newAesGcm(nil, err)
+	}
+	return newAesGcm(aead, nil)
+}
+
+func newAesGcm(aead cipher.AEAD, err error) AesGcm {
+	return AesGcm{
+		aead: aead,
+		err:  err,
+	}
+}
+
+func (g AesGcm) HasError() (error, bool) {
+	return g.err, g.err != nil
+}
+
+// If g has error, return 0.
+func (g AesGcm) NonceSize() int {
+	if g.err != nil {
+		return 0
+	}
+	return g.aead.NonceSize()
+}
+
+// The difference between the length of ciphertext and plaintext, which is the tag size.
+// If g has error, return 0.
+func (g AesGcm) Overhead() int {
+	if g.err != nil {
+		return 0
+	}
+	return g.aead.Overhead()
+}
+
+// nonce:
+// Its length must equal to NonceSize. Never use a nonce more than once with the same key.
+//
+// additionalData:
+// It is authenticated but not encrypted. It can be nil.
+//
+// The result is the ciphertext followed by the tag.
+// The result will not share the array of plaintext.
+func (g AesGcm) Seal(nonce, plaintext, additionalData []byte) ([]byte, error) {
+	if g.err != nil {
+		return nil, g.err
+	}
+	if len(nonce) != g.aead.NonceSize() {
+		return nil, errAesGcmNonceLenMustBeNonceSize
+	}
+	return g.aead.Seal(nil, nonce, plaintext, additionalData), nil
+}
+
+// The nonce and additionalData must be the same as the ones passed to Seal.
+// If the ciphertext or additionalData has been tampered, return ErrAuthenticationFailed.
+//
+// The result will not share the array of ciphertext.
+func (g AesGcm) Open(nonce, ciphertext, additionalData []byte) ([]byte, error) {
+	if g.err != nil {
+		return nil, g.err
+	}
+	if len(nonce) != g.aead.NonceSize() {
+		return nil, errAesGcmNonceLenMustBeNonceSize
+	}
+	if len(ciphertext) < g.aead.Overhead() {
+		return nil, errAesGcmCiphertextShorterThanTagLen
+	}
+	dst, err := g.aead.Open(nil, nonce, ciphertext, additionalData)
+	if err != nil {
+		return nil, ErrAuthenticationFailed
+	}
+	return dst, nil
+}
@@ -0,0 +1,291 @@
+package crypt
+
+import (
+	"crypto/aes"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+const (
+	AesGcmChunkedDefaultChunkSize = 64 * 1024 // Size is 64 KiB.
+	AesGcmChunkedMaxChunkSize     = 16 << 20  // Size is 16 MiB.
+	AesGcmChunkedSaltSize         = 16        // Size is 16 bytes.
+
+	// The version byte, the chunk size and the salt.
+	AesGcmChunkedHeaderSize = 1 + 4 + AesGcmChunkedSaltSize
+
+	aesGcmChunkedVersion1 = 1
+	aesGcmChunkedInfo     = "go-crypt aes-gcm chunked v1"
+)
+
+var (
+	errAesGcmChunkSizeIllegal       = errors.New("gcm chunk size illegal")
+	errAesGcmChunkedVersionUnknown  = errors.New("gcm chunked version unknown")
+	errAesGcmChunkedHeaderTruncated = errors.New("gcm chunked header truncated")
+	errAesGcmChunkedTooManyChunks   = errors.New("gcm chunked has too many chunks")
+	errAesGcmChunkedWriterClosed    = errors.New("gcm chunked writer closed")
+)
+
+// It implements the STREAM construction of online authenticated encryption with AES-GCM,
+// for the data which is too large to be sealed as one message.
+//
+// The layout of the output is:
+//
+//	version (1 byte) | chunk size (4 bytes, big endian) | salt (16 bytes) | chunk | chunk | ...
+//
+// Each chunk is the AES-GCM ciphertext of chunk size bytes of plaintext followed by the tag,
+// except that the last chunk may be shorter, even empty. The key of each output is derived
+// from the key param, the salt and the header by HKDF-SHA256. The nonce of each chunk is
+// 7 zero bytes, the chunk index (4 bytes, big endian) and a byte which is 1 for the last chunk
+// and 0 for the others. So reordered, truncated or extended output fails to be read.
+//
+// It may has an error, call HasError to see it.
+type AesGcmChunkedWriter struct {
+	w         io.Writer
+	gcm       AesGcm
+	chunkSize int
+	header    []byte // It is written before the first chunk, and set to nil after written.
+	buf       []byte // The plaintext which is not sealed yet.
+	index     uint32
+	closed    bool
+	err       error
+}
+
+// key:
+// The key must be either 16, 24, or 32 bytes to select AES-128, AES-192, or AES-256.
+//
+// chunkSize:
+// The byte size of plaintext in each chunk. If it is 0, AesGcmChunkedDefaultChunkSize is used.
+// It must not be greater than AesGcmChunkedMaxChunkSize.
+//
+// Can call HasError to see if it has an error.
+func NewAesGcmChunkedWriter(w io.Writer, key []byte, chunkSize int) *AesGcmChunkedWriter {
+	writer := &AesGcmChunkedWriter{
+		w: w,
+	}
+	if chunkSize == 0 {
+		chunkSize = AesGcmChunkedDefaultChunkSize
+	}
+	if chunkSize < 0 || chunkSize > AesGcmChunkedMaxChunkSize {
+		writer.err = errAesGcmChunkSizeIllegal
+		return writer
+	}
+	if _, err := aes.NewCipher(key); err != nil {
+		writer.err = err
+		return writer
+	}
+	header := make([]byte, AesGcmChunkedHeaderSize)
+	header[0] = aesGcmChunkedVersion1
+	binary.BigEndian.PutUint32(header[1:5], uint32(chunkSize))
+	if _, err := io.ReadFull(rand.Reader, header[5:]); err != nil {
+		writer.err = err
+		return writer
+	}
+	writer.gcm = newAesGcmChunkedAead(key, header)
+	writer.err, _ = writer.gcm.HasError()
+	writer.chunkSize = chunkSize
+	writer.header = header
+	return writer
+}
+
+func (w *AesGcmChunkedWriter) HasError() (error, bool) {
+	return w.err, w.err != nil
+}
+
+func (w *AesGcmChunkedWriter) Write(p []byte) (int, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+	if w.closed {
+		return 0, errAesGcmChunkedWriterClosed
+	}
+	w.buf = append(w.buf, p...)
+	// Keep the last full chunk in buf, because it may be the last chunk.
+	sealed := 0
+	for len(w.buf)-sealed > w.chunkSize {
+		if err := w.writeChunk(w.buf[sealed:(sealed+w.chunkSize)], false); err != nil {
+			return 0, err
+		}
+		sealed += w.chunkSize
+	}
+	w.buf = append(w.buf[:0], w.buf[sealed:]...)
+	return len(p), nil
+}
+
+// Seal and write the last chunk. It does not close the underlying writer.
+// Close more than once does nothing.
+func (w *AesGcmChunkedWriter) Close() error {
+	if w.err != nil {
+		return w.err
+	}
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	err := w.writeChunk(w.buf, true)
+	w.buf = nil
+	return err
+}
+
+func (w *AesGcmChunkedWriter) writeChunk(plaintext []byte, last bool) error {
+	if w.header != nil {
+		if err := writeFull(w.w, w.header); err != nil {
+			w.err = err
+			return err
+		}
+		w.header = nil
+	}
+	enc, err := w.gcm.Seal(aesGcmChunkedNonce(w.index, last), plaintext, nil)
+	if err == nil {
+		err = writeFull(w.w, enc)
+	}
+	if err == nil && !last {
+		if w.index == ^uint32(0) {
+			err = errAesGcmChunkedTooManyChunks
+		}
+		w.index++
+	}
+	if err != nil {
+		w.err = err
+	}
+	return err
+}
+
+// It reads the output of AesGcmChunkedWriter and opens it chunk by chunk.
+// Only the authenticated plaintext is returned. If the data has been tampered, reordered
+// or truncated, ErrAuthenticationFailed is returned when reading the broken chunk.
+//
+// It may has an error, call HasError to see it.
+type AesGcmChunkedReader struct {
+	r         io.Reader
+	key       []byte
+	gcm       AesGcm
+	chunkSize int
+	in        []byte // The ciphertext which is read but not opened yet.
+	out       []byte // The plaintext which is opened but not returned yet.
+	index     uint32
+	err       error
+}
+
+// key:
+// The key must be either 16, 24, or 32 bytes to select AES-128, AES-192, or AES-256.
+//
+// Can call HasError to see if it has an error.
+func NewAesGcmChunkedReader(r io.Reader, key []byte) *AesGcmChunkedReader {
+	reader := &AesGcmChunkedReader{
+		r: r,
+	}
+	if _, err := aes.NewCipher(key); err != nil {
+		reader.err = err
+		return reader
+	}
+	reader.key = append([]byte(nil), key...)
+	return reader
+}
+
+func (r *AesGcmChunkedReader) HasError() (error, bool) {
+	if r.err == io.EOF {
+		return nil, false
+	}
+	return r.err, r.err != nil
+}
+
+func (r *AesGcmChunkedReader) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	for len(r.out) == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+		if r.chunkSize == 0 {
+			r.err = r.readHeader()
+		} else {
+			r.out, r.err = r.readChunk()
+		}
+	}
+	n := copy(p, r.out)
+	r.out = r.out[n:]
+	return n, nil
+}
+
+func (r *AesGcmChunkedReader) readHeader() error {
+	header := make([]byte, AesGcmChunkedHeaderSize)
+	if _, err := io.ReadFull(r.r, header); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return errAesGcmChunkedHeaderTruncated
+		}
+		return err
+	}
+	if header[0] != aesGcmChunkedVersion1 {
+		return errAesGcmChunkedVersionUnknown
+	}
+	chunkSize := int(binary.BigEndian.Uint32(header[1:5]))
+	if chunkSize <= 0 || chunkSize > AesGcmChunkedMaxChunkSize {
+		return errAesGcmChunkSizeIllegal
+	}
+	r.gcm = newAesGcmChunkedAead(r.key, header)
+	if err, ok := r.gcm.HasError(); ok {
+		return err
+	}
+	r.chunkSize = chunkSize
+	// One more byte than a chunk is read, to know whether the chunk is the last one.
+	r.in = make([]byte, 0, chunkSize+r.gcm.Overhead()+1)
+	return nil
+}
+
+func (r *AesGcmChunkedReader) readChunk() ([]byte, error) {
+	start := len(r.in)
+	r.in = r.in[:cap(r.in)]
+	n, err := io.ReadFull(r.r, r.in[start:])
+	r.in = r.in[:(start + n)]
+	last := false
+	switch err {
+	case nil:
+	case io.EOF, io.ErrUnexpectedEOF:
+		last = true
+	default:
+		return nil, err
+	}
+	size := len(r.in)
+	if !last {
+		size--
+	}
+	dec, err := r.gcm.Open(aesGcmChunkedNonce(r.index, last), r.in[:size], nil)
+	if err != nil {
+		if err == errAesGcmCiphertextShorterThanTagLen {
+			return nil, ErrAuthenticationFailed
+		}
+		return nil, err
+	}
+	if last {
+		return dec, io.EOF
+	}
+	if r.index == ^uint32(0) {
+		return nil, errAesGcmChunkedTooManyChunks
+	}
+	r.index++
+	r.in = append(r.in[:0], r.in[size:]...)
+	return dec, nil
+}
+
+func newAesGcmChunkedAead(key, header []byte) AesGcm {
+	aeadKey, err := hkdf.Key(sha256.New, key, header[5:], aesGcmChunkedInfo+string(header[:5]), len(key))
+	if err != nil {
+		return AesGcm{err: err}
+	}
+	return NewAesGcm(aeadKey, 0, 0)
+}
+
+func aesGcmChunkedNonce(index uint32, last bool) []byte {
+	nonce := make([]byte, AesGcmStandardNonceSize)
+	binary.BigEndian.PutUint32(nonce[7:11], index)
+	if last {
+		nonce[11] = 1
+	}
+	return nonce
+}
@@ -0,0 +1,88 @@
+package crypt
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"testing/iotest"
+)
+
+func sealAesGcmChunked(t *testing.T, key, data []byte, chunkSize int) []byte {
+	var buf bytes.Buffer
+	w := NewAesGcmChunkedWriter(&buf, key, chunkSize)
+	if _, err := w.Write(data); err != nil {
+		t.Fatal("write:", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal("close:", err)
+	}
+	return buf.Bytes()
+}
+
+func TestAesGcmChunked(t *testing.T) {
+	key := []byte("11112222333344445555666677778888")
+	chunkSize := 16
+
+	for _, size := range []int{0, 1, chunkSize - 1, chunkSize, chunkSize + 1, chunkSize * 3} {
+		data := bytes.Repeat([]byte("s"), size)
+		enc := sealAesGcmChunked(t, key, data, chunkSize)
+		chunks := (size + chunkSize - 1) / chunkSize
+		if chunks == 0 {
+			chunks = 1 // The last chunk is empty.
+		}
+		if len(enc) != AesGcmChunkedHeaderSize+size+chunks*AesGcmStandardTagSize {
+			t.Error("sealed length is wrong with size", size, ":", len(enc))
+		}
+		dec, err := io.ReadAll(NewAesGcmChunkedReader(iotest.HalfReader(bytes.NewReader(enc)), key))
+		if err != nil {
+			t.Error("read all with size", size, ":", err)
+		}
+		if !bytes.Equal(dec, data) {
+			t.Error("read result is wrong with size", size)
+		}
+	}
+}
+
+func TestAesGcmChunkedIllegal(t *testing.T) {
+	key := []byte("11112222333344445555666677778888")
+	chunkSize := 16
+	data := bytes.Repeat([]byte("I love this girl! Does she?"), 3)
+	enc := sealAesGcmChunked(t, key, data, chunkSize)
+	encChunkSize := chunkSize + AesGcmStandardTagSize
+
+	t.Run("truncated", func(t *testing.T) {
+		buf := enc[:(AesGcmChunkedHeaderSize + encChunkSize*2)]
+		if _, err := io.ReadAll(NewAesGcmChunkedReader(bytes.NewReader(buf), key)); err != ErrAuthenticationFailed {
+			t.Error("read truncated err:", err)
+		}
+	})
+	t.Run("reordered", func(t *testing.T) {
+		buf := append([]byte(nil), enc...)
+		first := buf[AesGcmChunkedHeaderSize:(AesGcmChunkedHeaderSize + encChunkSize)]
+		second := buf[(AesGcmChunkedHeaderSize + encChunkSize):(AesGcmChunkedHeaderSize + encChunkSize*2)]
+		tmp := append([]byte(nil), first...)
+		copy(first, second)
+		copy(second, tmp)
+		if _, err := io.ReadAll(NewAesGcmChunkedReader(bytes.NewReader(buf), key)); err != ErrAuthenticationFailed {
+			t.Error("read reordered err:", err)
+		}
+	})
+	t.Run("header", func(t *testing.T) {
+		buf := append([]byte(nil), enc...)
+		buf[AesGcmChunkedHeaderSize-1] ^= 1
+		if _, err := io.ReadAll(NewAesGcmChunkedReader(bytes.NewReader(buf), key)); err != ErrAuthenticationFailed {
+			t.Error("read tampered header err:", err)
+		}
+	})
+	t.Run("key", func(t *testing.T) {
+		otherKey := bytes.Repeat([]byte("a"), Aes256KeySize)
+		if _, err := io.ReadAll(NewAesGcmChunkedReader(bytes.NewReader(enc), otherKey)); err != ErrAuthenticationFailed {
+			t.Error("read with wrong key err:", err)
+		}
+	})
+	t.Run("chunk size", func(t *testing.T) {
+		if _, ok := NewAesGcmChunkedWriter(io.Discard, key, AesGcmChunkedMaxChunkSize+1).HasError(); !ok {
+			t.Error("illegal chunk size should have error")
+		}
+	})
+}
@@ -0,0 +1,243 @@
+package crypt
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+)
+
+const (
+	AesGcmSivNonceSize = 12 // Size is 12 bytes.
+	AesGcmSivTagSize   = 16 // Size is 16 bytes.
+	// The maximum byte size of plaintext and additional data, see 6 of RFC 8452.
+	AesGcmSivMaxDataSize = 1 << 36
+)
+
+var (
+	errAesGcmSivKeySize            = errors.New("aes gcm siv key size must be 16 or 32")
+	errAesGcmSivNonceLen           = errors.New("aes gcm siv nonce length must be 12")
+	errAesGcmSivDataTooLong        = errors.New("aes gcm siv data too long")
+	errAesGcmSivCiphertextTooShort = errors.New("aes gcm siv ciphertext is shorter than tag")
+)
+
+// AES-GCM-SIV of RFC 8452. It is a nonce misuse resistant authenticated encryption:
+// reusing a nonce only reveals whether two messages with the same nonce are equal.
+//
+// It may has an error, call HasError to see it.
+type AesGcmSiv struct {
+	block   cipher.Block // The key-generating key.
+	keySize int
+	err     error
+}
+
+// key:
+// The key must be either 16 or 32 bytes to select AEAD_AES_128_GCM_SIV or AEAD_AES_256_GCM_SIV.
+//
+// Can call HasError to see if it has an error.
+func NewAesGcmSiv(key []byte) AesGcmSiv {
+	if len(key) != Aes128KeySize && len(key) != Aes256KeySize {
+		return AesGcmSiv{err: errAesGcmSivKeySize}
+	}
+	block, err := aes.NewCipher(key)
+	return AesGcmSiv{
+		block:   block,
+		keySize: len(key),
+		err:     err,
+	}
+}
+
+func (g AesGcmSiv) HasError() (error, bool) {
+	return g.err, g.err != nil
+}
+
+func (g AesGcmSiv) NonceSize() int {
+	return AesGcmSivNonceSize
+}
+
+// The ciphertext is longer than the plaintext by it.
+func (g AesGcmSiv) Overhead() int {
+	return AesGcmSivTagSize
+}
+
+// nonce:
+// Its length must be AesGcmSivNonceSize. It should be unique, but reusing it is not catastrophic.
+//
+// additionalData:
+// It is authenticated but not encrypted. It can be nil.
+//
+// The result is the ciphertext followed by the tag.
+// The result will not share the array of plaintext.
+func (g AesGcmSiv) Seal(nonce, plaintext, additionalData []byte) ([]byte, error) {
+	if err := g.check(nonce, plaintext, additionalData); err != nil {
+		return nil, err
+	}
+	authKey, encBlock, err := g.deriveKeys(nonce)
+	if err != nil {
+		return nil, err
+	}
+	tag := aesGcmSivTag(authKey, encBlock, nonce, plaintext, additionalData)
+	dst := make([]byte, len(plaintext), len(plaintext)+AesGcmSivTagSize)
+	aesGcmSivCtr(encBlock, tag, dst, plaintext)
+	return append(dst, tag...), nil
+}
+
+// The nonce and additionalData must be the same as the ones passed to Seal.
+// If the ciphertext or additionalData has been tampered, return ErrAuthenticationFailed.
+//
+// The result will not share the array of ciphertext.
+func (g AesGcmSiv) Open(nonce, ciphertext, additionalData []byte) ([]byte, error) {
+	if g.err != nil {
+		return nil, g.err
+	}
+	if len(ciphertext) < AesGcmSivTagSize {
+		return nil, errAesGcmSivCiphertextTooShort
+	}
+	enc, tag := ciphertext[:len(ciphertext)-AesGcmSivTagSize], ciphertext[len(ciphertext)-AesGcmSivTagSize:]
+	if err := g.check(nonce, enc, additionalData); err != nil {
+		return nil, err
+	}
+	authKey, encBlock, err := g.deriveKeys(nonce)
+	if err != nil {
+		return nil, err
+	}
+	dst := make([]byte, len(enc))
+	aesGcmSivCtr(encBlock, tag, dst, enc)
+	if subtle.ConstantTimeCompare(aesGcmSivTag(authKey, encBlock, nonce, dst, additionalData), tag) != 1 {
+		for i := range dst {
+			dst[i] = 0
+		}
+		return nil, ErrAuthenticationFailed
+	}
+	return dst, nil
+}
+
+func (g AesGcmSiv) check(nonce, plaintext, additionalData []byte) error {
+	if g.err != nil {
+		return g.err
+	}
+	if len(nonce) != AesGcmSivNonceSize {
+		return errAesGcmSivNonceLen
+	}
+	if uint64(len(plaintext)) > AesGcmSivMaxDataSize || uint64(len(additionalData)) > AesGcmSivMaxDataSize {
+		return errAesGcmSivDataTooLong
+	}
+	return nil
+}
+
+// Derive the message-authentication key and the message-encryption key of the nonce, see 4 of RFC 8452.
+func (g AesGcmSiv) deriveKeys(nonce []byte) ([]byte, cipher.Block, error) {
+	// The keys are 16 bytes of the authentication key, followed by the encryption key of the same size as the key.
+	// Each block of AES(key, LE32(i) | nonce) contributes its first 8 bytes.
+	blocks := (16 + g.keySize) / 8
+	keys := make([]byte, 0, blocks*8)
+	in := make([]byte, AesBlockSize)
+	out := make([]byte, AesBlockSize)
+	copy(in[4:], nonce)
+	for i := 0; i < blocks; i++ {
+		binary.LittleEndian.PutUint32(in, uint32(i))
+		g.block.Encrypt(out, in)
+		keys = append(keys, out[:8]...)
+	}
+	encBlock, err := aes.NewCipher(keys[16:])
+	if err != nil {
+		return nil, nil, err
+	}
+	return keys[:16], encBlock, nil
+}
+
+// The tag of 4 of RFC 8452.
+func aesGcmSivTag(authKey []byte, encBlock cipher.Block, nonce, plaintext, additionalData []byte) []byte {
+	var p polyval
+	p.init(authKey)
+	p.update(additionalData)
+	p.update(plaintext)
+	lengths := make([]byte, AesBlockSize)
+	binary.LittleEndian.PutUint64(lengths, uint64(len(additionalData))*8)
+	binary.LittleEndian.PutUint64(lengths[8:], uint64(len(plaintext))*8)
+	p.update(lengths)
+	s := p.sum()
+	subtle.XORBytes(s[:AesGcmSivNonceSize], s[:AesGcmSivNonceSize], nonce)
+	s[15] &= 0x7f
+	encBlock.Encrypt(s, s)
+	return s
+}
+
+// The CTR of GCM-SIV, whose counter is the first 32 bits in little endian of the initial block,
+// which is the tag with the most significant bit of the last byte set.
+func aesGcmSivCtr(encBlock cipher.Block, tag, dst, src []byte) {
+	counter := append([]byte(nil), tag...)
+	counter[15] |= 0x80
+	keyStream := make([]byte, AesBlockSize)
+	for i := 0; i < len(src); i += AesBlockSize {
+		encBlock.Encrypt(keyStream, counter)
+		end := i + AesBlockSize
+		if end > len(src) {
+			end = len(src)
+		}
+		subtle.XORBytes(dst[i:end], src[i:end], keyStream)
+		binary.LittleEndian.PutUint32(counter, binary.LittleEndian.Uint32(counter)+1)
+	}
+}
+
+// POLYVAL of 3 of RFC 8452. The field element is little endian, lo holds the bytes 0 to 7.
+type polyval struct {
+	hLo, hHi uint64 // H * x^-128, so that dot(S, H) is a plain multiplication by it.
+	sLo, sHi uint64
+}
+
+func (p *polyval) init(h []byte) {
+	p.hLo, p.hHi = polyvalMul(binary.LittleEndian.Uint64(h), binary.LittleEndian.Uint64(h[8:]), polyvalXInv128Lo, polyvalXInv128Hi)
+	p.sLo, p.sHi = 0, 0
+}
+
+// Absorb data padded by zeros to multiple of 16 bytes.
+func (p *polyval) update(data []byte) {
+	block := make([]byte, AesBlockSize)
+	for i := 0; i < len(data); i += AesBlockSize {
+		for j := range block {
+			block[j] = 0
+		}
+		copy(block, data[i:])
+		p.sLo ^= binary.LittleEndian.Uint64(block)
+		p.sHi ^= binary.LittleEndian.Uint64(block[8:])
+		p.sLo, p.sHi = polyvalMul(p.sLo, p.sHi, p.hLo, p.hHi)
+	}
+}
+
+func (p *polyval) sum() []byte {
+	s := make([]byte, AesBlockSize)
+	binary.LittleEndian.PutUint64(s, p.sLo)
+	binary.LittleEndian.PutUint64(s[8:], p.sHi)
+	return s
+}
+
+// x^-128 in the field of POLYVAL, which is x^127 + x^124 + x^121 + x^114 + 1.
+const (
+	polyvalXInv128Lo = 1
+	polyvalXInv128Hi = 1<<63 | 1<<60 | 1<<57 | 1<<50
+)
+
+// Multiply a by b modulo x^128 + x^127 + x^126 + x^121 + 1, in constant time.
+func polyvalMul(aLo, aHi, bLo, bHi uint64) (uint64, uint64) {
+	var rLo, rHi uint64
+	for i := 0; i < 128; i++ {
+		var bit uint64
+		if i < 64 {
+			bit = bLo >> uint(i) & 1
+		} else {
+			bit = bHi >> uint(i-64) & 1
+		}
+		mask := -bit
+		rLo ^= aLo & mask
+		rHi ^= aHi & mask
+		// a = a * x, and reduce x^128 to x^127 + x^126 + x^121 + 1.
+		carry := -(aHi >> 63)
+		aHi = aHi<<1 | aLo>>63
+		aLo <<= 1
+		aHi ^= 0xc200000000000000 & carry
+		aLo ^= 1 & carry
+	}
+	return rLo, rHi
+}
@@ -0,0 +1,107 @@
+package crypt
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+func TestAesGcmSiv(t *testing.T) {
+	// The vectors are of C.1 and C.2 of RFC 8452.
+	vectors := []struct {
+		key       string
+		plaintext string
+		ad        string
+		result    string
+	}{
+		{
+			key:    "01000000000000000000000000000000",
+			result: "dc20e2d83f25705bb49e439eca56de25",
+		},
+		{
+			key:       "01000000000000000000000000000000",
+			plaintext: "0100000000000000",
+			result:    "b5d839330ac7b786578782fff6013b815b287c22493a364c",
+		},
+		{
+			key:       "01000000000000000000000000000000",
+			plaintext: "0100000000000000000000000000000002000000000000000000000000000000",
+			result: "84e07e62ba83a6585417245d7ec413a9fe427d6315c09b57ce45f2e3936a9445" +
+				"1a8e45dcd4578c667cd86847bf6155ff",
+		},
+		{
+			key:       "01000000000000000000000000000000",
+			plaintext: "0200000000000000",
+			ad:        "01",
+			result:    "1e6daba35669f4273b0a1a2560969cdf790d99759abd1508",
+		},
+		{
+			key:    "0100000000000000000000000000000000000000000000000000000000000000",
+			result: "07f5f4169bbf55a8400cd47ea6fd400f",
+		},
+		{
+			key:       "0100000000000000000000000000000000000000000000000000000000000000",
+			plaintext: "0100000000000000",
+			result:    "c2ef328e5c71c83b843122130f7364b761e0b97427e3df28",
+		},
+	}
+	nonce, _ := hex.DecodeString("030000000000000000000000")
+	for _, v := range vectors {
+		key, _ := hex.DecodeString(v.key)
+		plaintext, _ := hex.DecodeString(v.plaintext)
+		ad, _ := hex.DecodeString(v.ad)
+		g := NewAesGcmSiv(key)
+		result, err := g.Seal(nonce, plaintext, ad)
+		if err != nil {
+			t.Fatal("seal err:", err)
+		}
+		if hex.EncodeToString(result) != v.result {
+			t.Error("seal wrong result:", len(key), v.plaintext, hex.EncodeToString(result))
+		}
+		dec, err := g.Open(nonce, result, ad)
+		if err != nil || !bytes.Equal(dec, plaintext) {
+			t.Error("open err:", err)
+		}
+		for i := range result {
+			tampered := append([]byte(nil), result...)
+			tampered[i] ^= 1
+			if _, err := g.Open(nonce, tampered, ad); err != ErrAuthenticationFailed {
+				t.Error("open tampered data err:", i, err)
+			}
+		}
+		if _, err := g.Open(nonce, result, []byte("other")); err != ErrAuthenticationFailed {
+			t.Error("open with other additional data err:", err)
+		}
+	}
+}
+
+func TestAesGcmSivNonceReuse(t *testing.T) {
+	g := NewAesGcmSiv(bytes.Repeat([]byte{1}, Aes256KeySize))
+	nonce := make([]byte, AesGcmSivNonceSize)
+	plaintext := bytes.Repeat([]byte("I love this girl! Does she?"), 10)
+	enc1, _ := g.Seal(nonce, plaintext, nil)
+	enc2, _ := g.Seal(nonce, plaintext, nil)
+	if !bytes.Equal(enc1, enc2) {
+		t.Error("the same nonce and plaintext should have the same result")
+	}
+	// Unlike CTR, the key stream is different for a different plaintext even with the same nonce.
+	other := append([]byte(nil), plaintext...)
+	other[len(other)-1] ^= 1
+	enc3, _ := g.Seal(nonce, other, nil)
+	if bytes.Equal(enc1[:16], enc3[:16]) {
+		t.Error("the different plaintext should have different key stream")
+	}
+	if dec, err := g.Open(nonce, enc3, nil); err != nil || !bytes.Equal(dec, other) {
+		t.Error("open err:", err)
+	}
+
+	if _, err := g.Seal(nonce[1:], plaintext, nil); err != errAesGcmSivNonceLen {
+		t.Error("seal with illegal nonce err:", err)
+	}
+	if _, err := g.Open(nonce, enc1[:AesGcmSivTagSize-1], nil); err != errAesGcmSivCiphertextTooShort {
+		t.Error("open short data err:", err)
+	}
+	if _, ok := NewAesGcmSiv(make([]byte, Aes192KeySize)).HasError(); !ok {
+		t.Error("illegal key size should have error")
+	}
+}
@@ -0,0 +1,101 @@
+package crypt
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+func TestAesGcm(t *testing.T) {
+	// Test Case 2 of The Galois/Counter Mode of Operation (GCM), McGrew and Viega.
+	key := make([]byte, Aes128KeySize)
+	nonce := make([]byte, AesGcmStandardNonceSize)
+	data := make([]byte, 16)
+	result := "0388dace60b6a392f328c2b971b2fe78" + "ab6e47d42cec13bdf53a67b21257bddf"
+
+	gcm := NewAesGcm(key, 0, 0)
+	if err, ok := gcm.HasError(); ok {
+		t.Fatal("new aes gcm:", err)
+	}
+	t.Run("seal", func(t *testing.T) {
+		enc, err := gcm.Seal(nonce, data, nil)
+		if err != nil {
+			t.Error("seal:", err)
+		}
+		str := hex.EncodeToString(enc)
+		if str != result {
+			t.Error("seal result is wrong:", str)
+		}
+	})
+	t.Run("open", func(t *testing.T) {
+		buf, _ := hex.DecodeString(result)
+		dec, err := gcm.Open(nonce, buf, nil)
+		if err != nil {
+			t.Error("open:", err)
+		}
+		if !bytes.Equal(dec, data) {
+			t.Error("open result is wrong:", dec)
+		}
+	})
+	t.Run("tampered", func(t *testing.T) {
+		buf, _ := hex.DecodeString(result)
+		buf[0] ^= 1
+		if _, err := gcm.Open(nonce, buf, nil); err != ErrAuthenticationFailed {
+			t.Error("open tampered ciphertext err:", err)
+		}
+	})
+}
+
+func TestAesGcmAdditionalData(t *testing.T) {
+	key := []byte("11112222333344445555666677778888")
+	nonce := []byte("123456781234")
+	data := []byte("I love this girl! Does she?")
+	ad := []byte("header")
+
+	gcm := NewAesGcm(key, 0, AesGcmMinTagSize)
+	enc, err := gcm.Seal(nonce, data, ad)
+	if err != nil {
+		t.Fatal("seal:", err)
+	}
+	if len(enc) != len(data)+AesGcmMinTagSize {
+		t.Error("sealed length is wrong:", len(enc))
+	}
+	dec, err := gcm.Open(nonce, enc, ad)
+	if err != nil {
+		t.Error("open:", err)
+	}
+	if !bytes.Equal(dec, data) {
+		t.Error("open result is wrong:", dec)
+	}
+	if _, err := gcm.Open(nonce, enc, []byte("other")); err != ErrAuthenticationFailed {
+		t.Error("open with wrong additional data err:", err)
+	}
+}
+
+func TestNewAesGcmIllegal(t *testing.T) {
+	key := []byte("11112222333344445555666677778888")
+	if _, ok := NewAesGcm(key[:15], 0, 0).HasError(); !ok {
+		t.Error("illegal key size should have error")
+	}
+	if _, ok := NewAesGcm(key, 0, 8).HasError(); !ok {
+		t.Error("illegal tag size should have error")
+	}
+	if _, ok := NewAesGcm(key, 16, 12).HasError(); !ok {
+		t.Error("non-standard nonce and tag size should have error")
+	}
+	if _, err := NewAesGcm(key, 0, 0).Seal(key[:8], nil, nil); err == nil {
+		t.Error("illegal nonce length should have error")
+	}
+}
+
+func BenchmarkAes256GcmSeal1000Bytes(b *testing.B) {
+	b.StopTimer()
+	key := bytes.Repeat([]byte("a"), Aes256KeySize)
+	nonce := bytes.Repeat([]byte("b"), AesGcmStandardNonceSize)
+	data := bytes.Repeat([]byte("s"), 1000)
+	gcm := NewAesGcm(key, 0, 0)
+	b.StartTimer()
+	for i := 0; i < b.N; i++ {
+		gcm.Seal(nonce, data, nil)
+	}
+}
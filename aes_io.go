@@ -0,0 +1,267 @@
+package crypt
+
+import (
+	"errors"
+	"io"
+)
+
+// The size of buffer to read from the underlying reader each time.
+const aesIoBufferSize = 32 * 1024
+
+var errAesWriterClosed = errors.New("aes writer closed")
+
+// It encrypts everything written to it with a block mode such as CBC, and writes the ciphertext
+// to the underlying writer. The data is written block by block, and the last block is padded
+// and written by Close.
+//
+// It may has an error, call HasError to see it.
+type AesEncryptWriter struct {
+	w      io.Writer
+	stream *AesBlockModeEncryptStream
+	buf    []byte // The plaintext which is less than a block and is not encrypted yet.
+	closed bool
+	err    error
+}
+
+// If encrypter has error, the writer has the same error.
+func NewAesEncryptWriter(w io.Writer, encrypter AesBlockModeEncrypter) *AesEncryptWriter {
+	return &AesEncryptWriter{
+		w:      w,
+		stream: encrypter.NewStream(),
+		err:    encrypter.err,
+	}
+}
+
+func (w *AesEncryptWriter) HasError() (error, bool) {
+	return w.err, w.err != nil
+}
+
+func (w *AesEncryptWriter) Write(p []byte) (int, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+	if w.closed {
+		return 0, errAesWriterClosed
+	}
+	w.buf = append(w.buf, p...)
+	size := len(w.buf) - len(w.buf)%AesBlockSize
+	if size == 0 {
+		return len(p), nil
+	}
+	enc, err := w.stream.EncryptBlocks(w.buf[:size])
+	if err != nil {
+		w.err = err
+		return 0, err
+	}
+	w.buf = append(w.buf[:0], w.buf[size:]...)
+	if err := writeFull(w.w, enc); err != nil {
+		w.err = err
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Pad and write the last block. It does not close the underlying writer.
+// Close more than once does nothing.
+func (w *AesEncryptWriter) Close() error {
+	if w.err != nil {
+		return w.err
+	}
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	enc, err := w.stream.EncryptFinal(w.buf)
+	if err != nil {
+		w.err = err
+		return err
+	}
+	w.buf = nil
+	if err := writeFull(w.w, enc); err != nil {
+		w.err = err
+		return err
+	}
+	return nil
+}
+
+// It reads the ciphertext of a block mode such as CBC from the underlying reader, and decrypts it.
+// The last block is held back until the underlying reader reaches EOF, then it is unpadded.
+// So the padding error or the data size error is returned when reading the end of the stream.
+//
+// It may has an error, call HasError to see it.
+type AesDecryptReader struct {
+	r      io.Reader
+	stream *AesBlockModeDecryptStream
+	chunk  []byte // The buffer to read from the underlying reader.
+	in     []byte // The ciphertext which is read but not decrypted yet.
+	out    []byte // The plaintext which is decrypted but not returned yet.
+	err    error
+}
+
+// If decrypter has error, the reader has the same error.
+func NewAesDecryptReader(r io.Reader, decrypter AesBlockModeDecrypter) *AesDecryptReader {
+	return &AesDecryptReader{
+		r:      r,
+		stream: decrypter.NewStream(),
+		err:    decrypter.err,
+	}
+}
+
+func (r *AesDecryptReader) HasError() (error, bool) {
+	if r.err == io.EOF {
+		return nil, false
+	}
+	return r.err, r.err != nil
+}
+
+func (r *AesDecryptReader) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	for len(r.out) == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+		r.fill()
+	}
+	n := copy(p, r.out)
+	r.out = r.out[n:]
+	return n, nil
+}
+
+func (r *AesDecryptReader) fill() {
+	if r.chunk == nil {
+		r.chunk = make([]byte, aesIoBufferSize)
+	}
+	n, err := r.r.Read(r.chunk)
+	r.in = append(r.in, r.chunk[:n]...)
+	if err == io.EOF {
+		r.out, r.err = r.stream.DecryptFinal(r.in)
+		if r.err == nil {
+			r.err = io.EOF
+		}
+		r.in = nil
+		return
+	}
+	if err != nil {
+		r.err = err
+		return
+	}
+	if len(r.in) == 0 {
+		return
+	}
+	// Hold back 1 to AesBlockSize bytes, which may be the last block.
+	size := (len(r.in) - 1) / AesBlockSize * AesBlockSize
+	if size == 0 {
+		return
+	}
+	r.out, r.err = r.stream.DecryptBlocks(r.in[:size])
+	r.in = append(r.in[:0], r.in[size:]...)
+}
+
+// It crypts everything written to it with a stream mode such as CTR, CFB or OFB,
+// and writes the result to the underlying writer.
+//
+// It may has an error, call HasError to see it.
+type AesStreamWriter struct {
+	w      io.Writer
+	stream *AesKeyStream
+	closed bool
+	err    error
+}
+
+// If s has error, the writer has the same error.
+func NewAesStreamWriter(w io.Writer, s AesStream) *AesStreamWriter {
+	return &AesStreamWriter{
+		w:      w,
+		stream: s.NewStream(),
+		err:    s.err,
+	}
+}
+
+func (w *AesStreamWriter) HasError() (error, bool) {
+	return w.err, w.err != nil
+}
+
+func (w *AesStreamWriter) Write(p []byte) (int, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+	if w.closed {
+		return 0, errAesWriterClosed
+	}
+	enc, err := w.stream.Crypt(p)
+	if err != nil {
+		w.err = err
+		return 0, err
+	}
+	if err := writeFull(w.w, enc); err != nil {
+		w.err = err
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// There is nothing to flush for a stream mode. It only makes the later Write fail,
+// so that it can be used like AesEncryptWriter. It does not close the underlying writer.
+func (w *AesStreamWriter) Close() error {
+	w.closed = true
+	return w.err
+}
+
+// It reads from the underlying reader, and crypts it with a stream mode such as CTR, CFB or OFB.
+//
+// It may has an error, call HasError to see it.
+type AesStreamReader struct {
+	r      io.Reader
+	stream *AesKeyStream
+	err    error
+}
+
+// If s has error, the reader has the same error.
+func NewAesStreamReader(r io.Reader, s AesStream) *AesStreamReader {
+	return &AesStreamReader{
+		r:      r,
+		stream: s.NewStream(),
+		err:    s.err,
+	}
+}
+
+func (r *AesStreamReader) HasError() (error, bool) {
+	if r.err == io.EOF {
+		return nil, false
+	}
+	return r.err, r.err != nil
+}
+
+func (r *AesStreamReader) Read(p []byte) (int, error) {
+	if r.err != nil {
+		return 0, r.err
+	}
+	n, err := r.r.Read(p)
+	if n > 0 {
+		dec, cryptErr := r.stream.Crypt(p[:n])
+		if cryptErr != nil {
+			r.err = cryptErr
+			return 0, cryptErr
+		}
+		copy(p, dec)
+	}
+	// The key stream has moved on, so the reader can not continue after an error.
+	if err != nil {
+		r.err = err
+	}
+	return n, err
+}
+
+// A short write breaks the key stream or the chaining state, so it is an error.
+func writeFull(w io.Writer, p []byte) error {
+	n, err := w.Write(p)
+	if err != nil {
+		return err
+	}
+	if n != len(p) {
+		return io.ErrShortWrite
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+package crypt
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+	"testing/iotest"
+)
+
+func TestAesEncryptWriterAndDecryptReader(t *testing.T) {
+	key := []byte("11112222333344445555666677778888")
+	iv := []byte("1234567812345678")
+	encrypter := NewAesCbcEncrypter(key, iv, NewPkcs7Padding(AesBlockSize))
+	decrypter := NewAesCbcDecrypter(key, iv, NewPkcs7Padding(AesBlockSize))
+
+	for _, size := range []int{0, 1, AesBlockSize, AesBlockSize*3 + 5, aesIoBufferSize + 7} {
+		data := bytes.Repeat([]byte("s"), size)
+		whole, _ := encrypter.Encrypt(data)
+
+		var buf bytes.Buffer
+		w := NewAesEncryptWriter(&buf, encrypter)
+		for i := 0; i < len(data); i += 7 {
+			end := i + 7
+			if end > len(data) {
+				end = len(data)
+			}
+			if _, err := w.Write(data[i:end]); err != nil {
+				t.Fatal("write:", err)
+			}
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal("close:", err)
+		}
+		if !bytes.Equal(buf.Bytes(), whole) {
+			t.Error("writer result is wrong with size", size)
+		}
+
+		dec, err := io.ReadAll(NewAesDecryptReader(iotest.HalfReader(bytes.NewReader(whole)), decrypter))
+		if err != nil {
+			t.Error("read all:", err)
+		}
+		if !bytes.Equal(dec, data) {
+			t.Error("reader result is wrong with size", size)
+		}
+	}
+}
+
+func TestAesDecryptReaderIllegal(t *testing.T) {
+	key := []byte("11112222333344445555666677778888")
+	iv := []byte("1234567812345678")
+	enc, _ := NewAesCbcEncrypter(key, iv, NewPkcs7Padding(AesBlockSize)).Encrypt(bytes.Repeat([]byte("s"), 40))
+	decrypter := NewAesCbcDecrypter(key, iv, NewPkcs7Padding(AesBlockSize))
+
+	t.Run("truncated", func(t *testing.T) {
+		_, err := io.ReadAll(NewAesDecryptReader(bytes.NewReader(enc[:len(enc)-1]), decrypter))
+		if err != errAesDataSizeMustBeMultipleOfBlockSize {
+			t.Error("read truncated ciphertext err:", err)
+		}
+	})
+	t.Run("padding", func(t *testing.T) {
+		buf := append([]byte(nil), enc...)
+		buf[len(buf)-AesBlockSize-1] ^= 0xff // Changes the last byte of the last plaintext block.
+		r := NewAesDecryptReader(bytes.NewReader(buf), decrypter)
+		dec, err := io.ReadAll(r)
+		if err != errPaddingIsWrong {
+			t.Error("read wrong padding err:", err)
+		}
+		if len(dec) != len(buf)-AesBlockSize {
+			t.Error("the blocks before the last one should be returned:", len(dec))
+		}
+	})
+}
+
+func TestAesStreamWriterAndReader(t *testing.T) {
+	key := []byte("11112222333344445555666677778888")
+	iv := []byte("1234567812345678")
+	data := bytes.Repeat([]byte("I love this girl! Does she?"), 100)
+
+	for name, pair := range map[string][2]AesStream{
+		"cfb": {NewAesCfbEncrypter(key, iv), NewAesCfbDecrypter(key, iv)},
+		"ofb": {NewAesOfb(key, iv), NewAesOfb(key, iv)},
+		"ctr": {NewAesCtr(key, iv), NewAesCtr(key, iv)},
+	} {
+		t.Run(name, func(t *testing.T) {
+			whole, _ := pair[0].Crypt(data)
+			var buf bytes.Buffer
+			w := NewAesStreamWriter(&buf, pair[0])
+			if _, err := io.Copy(w, iotest.OneByteReader(bytes.NewReader(data))); err != nil {
+				t.Fatal("copy:", err)
+			}
+			if err := w.Close(); err != nil {
+				t.Fatal("close:", err)
+			}
+			if !bytes.Equal(buf.Bytes(), whole) {
+				t.Error("writer result is wrong")
+			}
+			dec, err := io.ReadAll(NewAesStreamReader(iotest.HalfReader(&buf), pair[1]))
+			if err != nil {
+				t.Error("read all:", err)
+			}
+			if !bytes.Equal(dec, data) {
+				t.Error("reader result is wrong")
+			}
+		})
+	}
+}
+
+func TestAesStreamReaderError(t *testing.T) {
+	key := []byte("11112222333344445555666677778888")
+	iv := []byte("1234567812345678")
+	data := []byte("I love this girl! Does she?")
+	enc, _ := NewAesCtr(key, iv).Crypt(data)
+	errRead := errors.New("read failed")
+
+	r := NewAesStreamReader(io.MultiReader(bytes.NewReader(enc), iotest.ErrReader(errRead)), NewAesCtr(key, iv))
+	dec, err := io.ReadAll(r)
+	if err != errRead {
+		t.Error("read all should have the error of the underlying reader:", err)
+	}
+	if !bytes.Equal(dec, data) {
+		t.Error("the data before the error is wrong")
+	}
+	if err, ok := r.HasError(); !ok || err != errRead {
+		t.Error("the error is not recorded:", err)
+	}
+	if _, err := r.Read(make([]byte, 1)); err != errRead {
+		t.Error("read after the error:", err)
+	}
+
+	r = NewAesStreamReader(bytes.NewReader(enc), NewAesCtr(key, iv))
+	if _, err := io.ReadAll(r); err != nil {
+		t.Error("read all:", err)
+	}
+	if _, ok := r.HasError(); ok {
+		t.Error("EOF should not be an error")
+	}
+}
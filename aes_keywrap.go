@@ -0,0 +1,186 @@
+package crypt
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+)
+
+const (
+	AesKeyWrapSemiblockSize = 8 // The wrapped key is longer than the key by a semiblock.
+	AesKeyWrapMinKeySize    = 16
+)
+
+var (
+	errAesKeyWrapKeySize    = errors.New("aes key wrap key size must be multiple of 8 and not less than 16")
+	errAesKeyWrapKeyEmpty   = errors.New("aes key wrap key is empty")
+	errAesKeyWrapKeyTooLong = errors.New("aes key wrap key too long")
+	errAesKeyWrapWrappedLen = errors.New("aes key wrap wrapped key size illegal")
+)
+
+var (
+	aesKeyWrapIv         = []byte{0xa6, 0xa6, 0xa6, 0xa6, 0xa6, 0xa6, 0xa6, 0xa6} // See 2.2.3.1 of RFC 3394.
+	aesKeyWrapPaddingAiv = []byte{0xa6, 0x59, 0x59, 0xa6}                         // See 3 of RFC 5649.
+)
+
+// AES key wrap of RFC 3394, and AES key wrap with padding of RFC 5649.
+// It is the key wrap of JWE "A128KW", "A192KW" and "A256KW".
+//
+// It may has an error, call HasError to see it.
+type AesKeyWrap struct {
+	block cipher.Block
+	err   error
+}
+
+// kek:
+// The key encryption key. It must be either 16, 24, or 32 bytes to select AES-128, AES-192, or AES-256.
+//
+// Can call HasError to see if it has an error.
+func NewAesKeyWrap(kek []byte) AesKeyWrap {
+	block, err := aes.NewCipher(kek)
+	return AesKeyWrap{
+		block: block,
+		err:   err,
+	}
+}
+
+func (w AesKeyWrap) HasError() (error, bool) {
+	return w.err, w.err != nil
+}
+
+// Wrap key by RFC 3394. The size of key must be multiple of 8 and not less than 16, such as Aes128KeySize.
+// The result is 8 bytes longer than key.
+func (w AesKeyWrap) WrapKey(key []byte) ([]byte, error) {
+	if w.err != nil {
+		return nil, w.err
+	}
+	if len(key) < AesKeyWrapMinKeySize || len(key)%AesKeyWrapSemiblockSize != 0 {
+		return nil, errAesKeyWrapKeySize
+	}
+	return w.wrap(aesKeyWrapIv, key), nil
+}
+
+// Unwrap the result of WrapKey.
+// If the integrity check fails, which means the kek is wrong or wrapped has been tampered, return ErrAuthenticationFailed.
+func (w AesKeyWrap) UnwrapKey(wrapped []byte) ([]byte, error) {
+	if w.err != nil {
+		return nil, w.err
+	}
+	if len(wrapped) < AesKeyWrapMinKeySize+AesKeyWrapSemiblockSize || len(wrapped)%AesKeyWrapSemiblockSize != 0 {
+		return nil, errAesKeyWrapWrappedLen
+	}
+	a, key := w.unwrap(wrapped)
+	if subtle.ConstantTimeCompare(a, aesKeyWrapIv) != 1 {
+		return nil, ErrAuthenticationFailed
+	}
+	return key, nil
+}
+
+// Wrap key of any size by RFC 5649. The key must not be empty.
+// The result is of the size of key rounded up to multiple of 8, plus 8 bytes.
+func (w AesKeyWrap) WrapKeyWithPadding(key []byte) ([]byte, error) {
+	if w.err != nil {
+		return nil, w.err
+	}
+	if len(key) == 0 {
+		return nil, errAesKeyWrapKeyEmpty
+	}
+	if uint64(len(key)) > 0xffffffff {
+		return nil, errAesKeyWrapKeyTooLong
+	}
+	// AIV = 0xa65959a6 || 32 bits big endian of the size of key.
+	aiv := make([]byte, AesKeyWrapSemiblockSize)
+	copy(aiv, aesKeyWrapPaddingAiv)
+	binary.BigEndian.PutUint32(aiv[4:], uint32(len(key)))
+	padded := make([]byte, (len(key)+AesKeyWrapSemiblockSize-1)/AesKeyWrapSemiblockSize*AesKeyWrapSemiblockSize)
+	copy(padded, key)
+	if len(padded) == AesKeyWrapSemiblockSize {
+		// A single semiblock is encrypted with AIV as a block.
+		result := append(aiv, padded...)
+		w.block.Encrypt(result, result)
+		return result, nil
+	}
+	return w.wrap(aiv, padded), nil
+}
+
+// Unwrap the result of WrapKeyWithPadding.
+// If the integrity check fails, which means the kek is wrong or wrapped has been tampered, return ErrAuthenticationFailed.
+func (w AesKeyWrap) UnwrapKeyWithPadding(wrapped []byte) ([]byte, error) {
+	if w.err != nil {
+		return nil, w.err
+	}
+	if len(wrapped) < 2*AesKeyWrapSemiblockSize || len(wrapped)%AesKeyWrapSemiblockSize != 0 {
+		return nil, errAesKeyWrapWrappedLen
+	}
+	var a, padded []byte
+	if len(wrapped) == 2*AesKeyWrapSemiblockSize {
+		buf := make([]byte, len(wrapped))
+		w.block.Decrypt(buf, wrapped)
+		a, padded = buf[:AesKeyWrapSemiblockSize], buf[AesKeyWrapSemiblockSize:]
+	} else {
+		a, padded = w.unwrap(wrapped)
+	}
+	// Check the AIV, that the size is in the last semiblock, and that the padding is zeros.
+	good := subtle.ConstantTimeCompare(a[:4], aesKeyWrapPaddingAiv)
+	size := binary.BigEndian.Uint32(a[4:])
+	paddingSize := uint64(len(padded)) - uint64(size)
+	if uint64(size) > uint64(len(padded)) || paddingSize >= AesKeyWrapSemiblockSize {
+		good = 0
+		paddingSize = 0
+	}
+	good &= constantTimeTailIs(padded, int(paddingSize), 0)
+	if good != 1 {
+		return nil, ErrAuthenticationFailed
+	}
+	return padded[:size], nil
+}
+
+// The wrapping process of 2.2.1 of RFC 3394, with the index based procedure.
+// The size of plaintext must be multiple of 8 and not less than 16.
+func (w AesKeyWrap) wrap(iv, plaintext []byte) []byte {
+	n := len(plaintext) / AesKeyWrapSemiblockSize
+	result := make([]byte, AesKeyWrapSemiblockSize+len(plaintext))
+	a := result[:AesKeyWrapSemiblockSize]
+	copy(a, iv)
+	copy(result[AesKeyWrapSemiblockSize:], plaintext)
+	b := make([]byte, aes.BlockSize)
+	for j := 0; j < 6; j++ {
+		for i := 1; i <= n; i++ {
+			r := result[i*AesKeyWrapSemiblockSize : (i+1)*AesKeyWrapSemiblockSize]
+			// B = AES(K, A | R[i]), A = MSB(64, B) ^ t, R[i] = LSB(64, B).
+			copy(b, a)
+			copy(b[AesKeyWrapSemiblockSize:], r)
+			w.block.Encrypt(b, b)
+			t := uint64(n*j + i)
+			binary.BigEndian.PutUint64(a, binary.BigEndian.Uint64(b)^t)
+			copy(r, b[AesKeyWrapSemiblockSize:])
+		}
+	}
+	return result
+}
+
+// The unwrapping process of 2.2.2 of RFC 3394, with the index based procedure.
+// Return the integrity check register and the plaintext, which are not checked.
+func (w AesKeyWrap) unwrap(ciphertext []byte) ([]byte, []byte) {
+	n := len(ciphertext)/AesKeyWrapSemiblockSize - 1
+	a := make([]byte, AesKeyWrapSemiblockSize)
+	copy(a, ciphertext)
+	plaintext := make([]byte, n*AesKeyWrapSemiblockSize)
+	copy(plaintext, ciphertext[AesKeyWrapSemiblockSize:])
+	b := make([]byte, aes.BlockSize)
+	for j := 5; j >= 0; j-- {
+		for i := n; i >= 1; i-- {
+			r := plaintext[(i-1)*AesKeyWrapSemiblockSize : i*AesKeyWrapSemiblockSize]
+			// B = AES-1(K, (A ^ t) | R[i]), A = MSB(64, B), R[i] = LSB(64, B).
+			t := uint64(n*j + i)
+			binary.BigEndian.PutUint64(b, binary.BigEndian.Uint64(a)^t)
+			copy(b[AesKeyWrapSemiblockSize:], r)
+			w.block.Decrypt(b, b)
+			copy(a, b)
+			copy(r, b[AesKeyWrapSemiblockSize:])
+		}
+	}
+	return a, plaintext
+}
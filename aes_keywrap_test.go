@@ -0,0 +1,134 @@
+package crypt
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+func TestAesKeyWrap(t *testing.T) {
+	// The vectors are of 4 of RFC 3394.
+	vectors := []struct {
+		kek     string
+		key     string
+		wrapped string
+	}{
+		{
+			kek:     "000102030405060708090a0b0c0d0e0f",
+			key:     "00112233445566778899aabbccddeeff",
+			wrapped: "1fa68b0a8112b447aef34bd8fb5a7b829d3e862371d2cfe5",
+		},
+		{
+			kek:     "000102030405060708090a0b0c0d0e0f1011121314151617",
+			key:     "00112233445566778899aabbccddeeff0001020304050607",
+			wrapped: "031d33264e15d33268f24ec260743edce1c6c7ddee725a936ba814915c6762d2",
+		},
+		{
+			kek:     "000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f",
+			key:     "00112233445566778899aabbccddeeff000102030405060708090a0b0c0d0e0f",
+			wrapped: "28c9f404c4b810f4cbccb35cfb87f8263f5786e2d80ed326cbc7f0e71a99f43bfb988b9b7a02dd21",
+		},
+	}
+	for _, v := range vectors {
+		kek, _ := hex.DecodeString(v.kek)
+		key, _ := hex.DecodeString(v.key)
+		kw := NewAesKeyWrap(kek)
+		wrapped, err := kw.WrapKey(key)
+		if err != nil {
+			t.Fatal("wrap err:", err)
+		}
+		if hex.EncodeToString(wrapped) != v.wrapped {
+			t.Error("wrap wrong result:", hex.EncodeToString(wrapped))
+		}
+		unwrapped, err := kw.UnwrapKey(wrapped)
+		if err != nil || !bytes.Equal(unwrapped, key) {
+			t.Error("unwrap err:", err)
+		}
+		for i := range wrapped {
+			tampered := append([]byte(nil), wrapped...)
+			tampered[i] ^= 1
+			if _, err := kw.UnwrapKey(tampered); err != ErrAuthenticationFailed {
+				t.Error("unwrap tampered key err:", i, err)
+			}
+		}
+	}
+
+	kw := NewAesKeyWrap(make([]byte, Aes256KeySize))
+	for _, size := range []int{0, 8, 17} {
+		if _, err := kw.WrapKey(make([]byte, size)); err != errAesKeyWrapKeySize {
+			t.Error("wrap illegal key size err:", size, err)
+		}
+	}
+	for _, size := range []int{16, 25} {
+		if _, err := kw.UnwrapKey(make([]byte, size)); err != errAesKeyWrapWrappedLen {
+			t.Error("unwrap illegal size err:", size, err)
+		}
+	}
+	if _, ok := NewAesKeyWrap(make([]byte, 10)).HasError(); !ok {
+		t.Error("illegal kek should have error")
+	}
+}
+
+func TestAesKeyWrapWithPadding(t *testing.T) {
+	// The vectors are of 6 of RFC 5649.
+	kek, _ := hex.DecodeString("5840df6e29b02af1ab493b705bf16ea1ae8338f4dcc176a8")
+	vectors := []struct {
+		key     string
+		wrapped string
+	}{
+		{
+			key:     "c37b7e6492584340bed12207808941155068f738",
+			wrapped: "138bdeaa9b8fa7fc61f97742e72248ee5ae6ae5360d1ae6a5f54f373fa543b6a",
+		},
+		{
+			key:     "466f7250617369",
+			wrapped: "afbeb0f07dfbf5419200f2ccb50bb24f",
+		},
+	}
+	kw := NewAesKeyWrap(kek)
+	for _, v := range vectors {
+		key, _ := hex.DecodeString(v.key)
+		wrapped, err := kw.WrapKeyWithPadding(key)
+		if err != nil {
+			t.Fatal("wrap err:", err)
+		}
+		if hex.EncodeToString(wrapped) != v.wrapped {
+			t.Error("wrap wrong result:", hex.EncodeToString(wrapped))
+		}
+		unwrapped, err := kw.UnwrapKeyWithPadding(wrapped)
+		if err != nil || !bytes.Equal(unwrapped, key) {
+			t.Error("unwrap err:", err)
+		}
+		for i := range wrapped {
+			tampered := append([]byte(nil), wrapped...)
+			tampered[i] ^= 1
+			if _, err := kw.UnwrapKeyWithPadding(tampered); err != ErrAuthenticationFailed {
+				t.Error("unwrap tampered key err:", i, err)
+			}
+		}
+		// The wrapping of RFC 3394 can not be unwrapped with padding.
+		if len(key)%8 == 0 {
+			wrapped, _ = kw.WrapKey(key)
+			if _, err := kw.UnwrapKeyWithPadding(wrapped); err != ErrAuthenticationFailed {
+				t.Error("unwrap the key wrapped without padding err:", err)
+			}
+		}
+	}
+
+	for size := 1; size <= 40; size++ {
+		key := bytes.Repeat([]byte{byte(size)}, size)
+		wrapped, err := kw.WrapKeyWithPadding(key)
+		if err != nil || len(wrapped) != (size+7)/8*8+8 {
+			t.Fatal("wrap err:", size, err)
+		}
+		if unwrapped, err := kw.UnwrapKeyWithPadding(wrapped); err != nil || !bytes.Equal(unwrapped, key) {
+			t.Error("unwrap err:", size, err)
+		}
+	}
+	if _, err := kw.WrapKeyWithPadding(nil); err != errAesKeyWrapKeyEmpty {
+		t.Error("wrap empty key err:", err)
+	}
+	if _, err := kw.UnwrapKeyWithPadding(make([]byte, 8)); err != errAesKeyWrapWrappedLen {
+		t.Error("unwrap illegal size err:", err)
+	}
+}
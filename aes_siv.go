@@ -0,0 +1,179 @@
+package crypt
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/subtle"
+	"errors"
+)
+
+const (
+	AesSivTagSize              = 16  // Size is 16 bytes.
+	AesSivMaxAdditionalDataNum = 126 // See 7 of RFC 5297.
+)
+
+var (
+	errAesSivKeySize            = errors.New("aes siv key size must be 32, 48 or 64")
+	errAesSivTooManyAdditional  = errors.New("aes siv has too many additional data")
+	errAesSivCiphertextTooShort = errors.New("aes siv ciphertext is shorter than tag")
+)
+
+// AES-SIV of RFC 5297. It is a deterministic authenticated encryption:
+// the same plaintext and additional data are always encrypted to the same ciphertext,
+// so that reusing a nonce only reveals whether two messages are equal.
+//
+// It may has an error, call HasError to see it.
+type AesSiv struct {
+	macBlock cipher.Block
+	ctrKey   []byte
+	err      error
+}
+
+// key:
+// The key must be either 32, 48, or 64 bytes to select AES-SIV-256, AES-SIV-384, or AES-SIV-512.
+// The first half is the key of S2V, and the second half is the key of CTR.
+//
+// Can call HasError to see if it has an error.
+func NewAesSiv(key []byte) AesSiv {
+	switch len(key) {
+	case 2 * Aes128KeySize, 2 * Aes192KeySize, 2 * Aes256KeySize:
+	default:
+		return AesSiv{err: errAesSivKeySize}
+	}
+	half := len(key) / 2
+	macBlock, err := aes.NewCipher(key[:half])
+	if err != nil {
+		return AesSiv{err: err}
+	}
+	return AesSiv{
+		macBlock: macBlock,
+		ctrKey:   append([]byte(nil), key[half:]...),
+	}
+}
+
+func (s AesSiv) HasError() (error, bool) {
+	return s.err, s.err != nil
+}
+
+// The ciphertext is longer than the plaintext by it.
+func (s AesSiv) Overhead() int {
+	return AesSivTagSize
+}
+
+// additionalData:
+// Each of them is authenticated separately but not encrypted, there can be at most AesSivMaxAdditionalDataNum of them.
+// To use a nonce, pass it as the last one. Without a nonce, the encryption is deterministic.
+//
+// The result is the synthetic IV followed by the ciphertext, as in RFC 5297.
+// The result will not share the array of plaintext.
+func (s AesSiv) Seal(plaintext []byte, additionalData ...[]byte) ([]byte, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	if len(additionalData) > AesSivMaxAdditionalDataNum {
+		return nil, errAesSivTooManyAdditional
+	}
+	v := s.s2v(plaintext, additionalData)
+	enc, err := NewAesCtr(s.ctrKey, aesSivCtrIv(v)).Crypt(plaintext)
+	if err != nil {
+		return nil, err
+	}
+	return append(v, enc...), nil
+}
+
+// The additionalData must be the same as the ones passed to Seal, in the same order.
+// If the ciphertext or additionalData has been tampered, return ErrAuthenticationFailed.
+//
+// The result will not share the array of ciphertext.
+func (s AesSiv) Open(ciphertext []byte, additionalData ...[]byte) ([]byte, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	if len(additionalData) > AesSivMaxAdditionalDataNum {
+		return nil, errAesSivTooManyAdditional
+	}
+	if len(ciphertext) < AesSivTagSize {
+		return nil, errAesSivCiphertextTooShort
+	}
+	v := ciphertext[:AesSivTagSize]
+	dst, err := NewAesCtr(s.ctrKey, aesSivCtrIv(v)).Crypt(ciphertext[AesSivTagSize:])
+	if err != nil {
+		return nil, err
+	}
+	if subtle.ConstantTimeCompare(s.s2v(dst, additionalData), v) != 1 {
+		return nil, ErrAuthenticationFailed
+	}
+	return dst, nil
+}
+
+// S2V of 2.4 of RFC 5297, the plaintext is the last string.
+func (s AesSiv) s2v(plaintext []byte, additionalData [][]byte) []byte {
+	d := aesCmac(s.macBlock, make([]byte, AesBlockSize))
+	for _, ad := range additionalData {
+		aesSivDbl(d)
+		subtle.XORBytes(d, d, aesCmac(s.macBlock, ad))
+	}
+	var t []byte
+	if len(plaintext) >= AesBlockSize {
+		// T = plaintext xorend D.
+		t = append([]byte(nil), plaintext...)
+		tail := t[len(t)-AesBlockSize:]
+		subtle.XORBytes(tail, tail, d)
+	} else {
+		// T = dbl(D) xor pad(plaintext).
+		aesSivDbl(d)
+		t = make([]byte, AesBlockSize)
+		copy(t, plaintext)
+		t[len(plaintext)] = 0x80
+		subtle.XORBytes(t, t, d)
+	}
+	return aesCmac(s.macBlock, t)
+}
+
+// Clear the 31st and 63rd bits from the right of v, see 2.6 of RFC 5297.
+func aesSivCtrIv(v []byte) []byte {
+	iv := append([]byte(nil), v...)
+	iv[8] &= 0x7f
+	iv[12] &= 0x7f
+	return iv
+}
+
+// Multiply b by x in GF(2^128), see 2.3 of RFC 5297. The b is modified.
+func aesSivDbl(b []byte) {
+	msb := b[0] >> 7
+	for i := 0; i < len(b)-1; i++ {
+		b[i] = b[i]<<1 | b[i+1]>>7
+	}
+	// If the most significant bit is 1, xor 0x87, which is done in constant time.
+	b[len(b)-1] = b[len(b)-1]<<1 ^ (0x87 & -msb)
+}
+
+// AES-CMAC of RFC 4493.
+func aesCmac(block cipher.Block, msg []byte) []byte {
+	k1 := make([]byte, AesBlockSize)
+	block.Encrypt(k1, k1)
+	aesSivDbl(k1)
+	k2 := append([]byte(nil), k1...)
+	aesSivDbl(k2)
+
+	n := (len(msg) + AesBlockSize - 1) / AesBlockSize
+	last := make([]byte, AesBlockSize)
+	if n > 0 && len(msg)%AesBlockSize == 0 {
+		subtle.XORBytes(last, msg[(n-1)*AesBlockSize:], k1)
+	} else {
+		if n == 0 {
+			n = 1
+		}
+		copy(last, msg[(n-1)*AesBlockSize:])
+		last[len(msg)-(n-1)*AesBlockSize] = 0x80
+		subtle.XORBytes(last, last, k2)
+	}
+	x := make([]byte, AesBlockSize)
+	for i := 0; i < n-1; i++ {
+		subtle.XORBytes(x, x, msg[i*AesBlockSize:(i+1)*AesBlockSize])
+		block.Encrypt(x, x)
+	}
+	subtle.XORBytes(x, x, last)
+	block.Encrypt(x, x)
+	return x
+}
@@ -0,0 +1,100 @@
+package crypt
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+func TestAesSiv(t *testing.T) {
+	// The vectors are of A.1 and A.2 of RFC 5297.
+	vectors := []struct {
+		key       string
+		ads       []string
+		plaintext string
+		result    string
+	}{
+		{
+			key:       "fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff",
+			ads:       []string{"101112131415161718191a1b1c1d1e1f2021222324252627"},
+			plaintext: "112233445566778899aabbccddee",
+			result:    "85632d07c6e8f37f950acd320a2ecc9340c02b9690c4dc04daef7f6afe5c",
+		},
+		{
+			key: "7f7e7d7c7b7a79787776757473727170404142434445464748494a4b4c4d4e4f",
+			ads: []string{
+				"00112233445566778899aabbccddeeffdeaddadadeaddadaffeeddccbbaa99887766554433221100",
+				"102030405060708090a0",
+				"09f911029d74e35bd84156c5635688c0", // The nonce.
+			},
+			plaintext: "7468697320697320736f6d6520706c61696e7465787420746f20656e6372797074207573696e67205349562d414553",
+			result: "7bdb6e3b432667eb06f4d14bff2fbd0fcb900f2fddbe404326601965c889bf17" +
+				"dba77ceb094fa663b7a3f748ba8af829ea64ad544a272e9c485b62a3fd5c0d",
+		},
+	}
+	for _, v := range vectors {
+		key, _ := hex.DecodeString(v.key)
+		plaintext, _ := hex.DecodeString(v.plaintext)
+		var ads [][]byte
+		for _, ad := range v.ads {
+			b, _ := hex.DecodeString(ad)
+			ads = append(ads, b)
+		}
+		s := NewAesSiv(key)
+		result, err := s.Seal(plaintext, ads...)
+		if err != nil {
+			t.Fatal("seal err:", err)
+		}
+		if hex.EncodeToString(result) != v.result {
+			t.Error("seal wrong result:", hex.EncodeToString(result))
+		}
+		dec, err := s.Open(result, ads...)
+		if err != nil || !bytes.Equal(dec, plaintext) {
+			t.Error("open err:", err)
+		}
+		for i := range result {
+			tampered := append([]byte(nil), result...)
+			tampered[i] ^= 1
+			if _, err := s.Open(tampered, ads...); err != ErrAuthenticationFailed {
+				t.Error("open tampered data err:", i, err)
+			}
+		}
+		// The order and the boundaries of the additional data are authenticated.
+		if _, err := s.Open(result, append(ads, nil)...); err != ErrAuthenticationFailed {
+			t.Error("open with more additional data err:", err)
+		}
+		if _, err := s.Open(result, bytes.Join(ads, nil)); len(ads) > 1 && err != ErrAuthenticationFailed {
+			t.Error("open with joined additional data err:", err)
+		}
+	}
+}
+
+func TestAesSivSizes(t *testing.T) {
+	for _, keySize := range []int{32, 48, 64} {
+		s := NewAesSiv(bytes.Repeat([]byte{1}, keySize))
+		for size := 0; size <= 40; size++ {
+			plaintext := bytes.Repeat([]byte{byte(size)}, size)
+			result, err := s.Seal(plaintext)
+			if err != nil || len(result) != size+s.Overhead() {
+				t.Fatal("seal err:", keySize, size, err)
+			}
+			if again, _ := s.Seal(plaintext); !bytes.Equal(again, result) {
+				t.Error("seal is not deterministic:", keySize, size)
+			}
+			if dec, err := s.Open(result); err != nil || !bytes.Equal(dec, plaintext) {
+				t.Error("open err:", keySize, size, err)
+			}
+		}
+	}
+
+	s := NewAesSiv(make([]byte, 32))
+	if _, err := s.Seal(nil, make([][]byte, AesSivMaxAdditionalDataNum+1)...); err != errAesSivTooManyAdditional {
+		t.Error("seal with too many additional data err:", err)
+	}
+	if _, err := s.Open(make([]byte, AesSivTagSize-1)); err != errAesSivCiphertextTooShort {
+		t.Error("open short data err:", err)
+	}
+	if _, ok := NewAesSiv(make([]byte, 16)).HasError(); !ok {
+		t.Error("illegal key size should have error")
+	}
+}
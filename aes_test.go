@@ -126,6 +126,159 @@ func TestAesCtr(t *testing.T) {
 	})
 }
 
+func TestAesCbcEncryptTwice(t *testing.T) {
+	key := []byte("11112222333344445555666677778888")
+	iv := []byte("1234567812345678")
+	data := "I love this girl! Does she?"
+	result := "DC9HZuq4EOq7fO+vP2Qs2Oh9zfaA8TI/u6tHN38yvcM="
+
+	encrypter := NewAesCbcEncrypter(key, iv, NewPkcs7Padding(AesBlockSize))
+	decrypter := NewAesCbcDecrypter(key, iv, NewPkcs7Padding(AesBlockSize))
+	for i := 0; i < 2; i++ {
+		enc, err := encrypter.Encrypt([]byte(data))
+		if err != nil {
+			t.Error("encrypt:", err)
+		}
+		if str := base64.StdEncoding.EncodeToString(enc); str != result {
+			t.Error("encrypt result is wrong at call", i, ":", str)
+		}
+		dec, err := decrypter.Decrypt(enc)
+		if err != nil {
+			t.Error("decrypt:", err)
+		}
+		if string(dec) != data {
+			t.Error("decrypt result is wrong at call", i, ":", dec)
+		}
+	}
+}
+
+func TestAesCbcWithIvAndReset(t *testing.T) {
+	key := []byte("11112222333344445555666677778888")
+	iv := []byte("1234567812345678")
+	iv2 := []byte("8765432187654321")
+	data := []byte("I love this girl! Does she?")
+
+	encrypter := NewAesCbcEncrypter(key, iv, NewPkcs7Padding(AesBlockSize))
+	enc, err := encrypter.EncryptWithIv(iv2, data)
+	if err != nil {
+		t.Fatal("encrypt with iv:", err)
+	}
+	if err := encrypter.Reset(iv2); err != nil {
+		t.Fatal("reset:", err)
+	}
+	enc2, err := encrypter.Encrypt(data)
+	if err != nil {
+		t.Fatal("encrypt:", err)
+	}
+	if !bytes.Equal(enc, enc2) {
+		t.Error("encrypt after reset should use the new iv")
+	}
+	dec, err := NewAesCbcDecrypter(key, iv, NewPkcs7Padding(AesBlockSize)).DecryptWithIv(iv2, enc)
+	if err != nil {
+		t.Error("decrypt with iv:", err)
+	}
+	if !bytes.Equal(dec, data) {
+		t.Error("decrypt result is wrong:", dec)
+	}
+	if err := encrypter.Reset(iv2[:8]); err == nil {
+		t.Error("reset with illegal iv should have error")
+	}
+}
+
+func TestAesCbcStream(t *testing.T) {
+	key := []byte("11112222333344445555666677778888")
+	iv := []byte("1234567812345678")
+	data := []byte("I love this girl! Does she?")
+
+	encrypter := NewAesCbcEncrypter(key, iv, NewPkcs7Padding(AesBlockSize))
+	whole, _ := encrypter.Encrypt(data)
+	stream := encrypter.NewStream()
+	for i := 0; i < 2; i++ {
+		part1, err := stream.EncryptBlocks(data[:AesBlockSize])
+		if err != nil {
+			t.Fatal("encrypt blocks:", err)
+		}
+		part2, err := stream.EncryptFinal(data[AesBlockSize:])
+		if err != nil {
+			t.Fatal("encrypt final:", err)
+		}
+		if enc := append(part1, part2...); !bytes.Equal(enc, whole) {
+			t.Error("stream encrypt result is wrong at message", i, ":", enc)
+		}
+	}
+	if _, err := stream.EncryptBlocks(data[:3]); err == nil {
+		t.Error("encrypt blocks with partial block should have error")
+	}
+
+	decStream := NewAesCbcDecrypter(key, iv, NewPkcs7Padding(AesBlockSize)).NewStream()
+	part1, err := decStream.DecryptBlocks(whole[:AesBlockSize])
+	if err != nil {
+		t.Fatal("decrypt blocks:", err)
+	}
+	part2, err := decStream.DecryptFinal(whole[AesBlockSize:])
+	if err != nil {
+		t.Fatal("decrypt final:", err)
+	}
+	if dec := append(part1, part2...); !bytes.Equal(dec, data) {
+		t.Error("stream decrypt result is wrong:", dec)
+	}
+
+	stream.EncryptBlocks(data[:AesBlockSize])
+	if err := stream.Reset(nil); err != nil {
+		t.Fatal("reset:", err)
+	}
+	part1, _ = stream.EncryptBlocks(data[:AesBlockSize])
+	if !bytes.Equal(part1, whole[:AesBlockSize]) {
+		t.Error("stream should restart from the iv after reset")
+	}
+}
+
+func TestAesCtrCryptTwice(t *testing.T) {
+	key := []byte("11112222333344445555666677778888")
+	iv := []byte("1234567812345678")
+	data := "I love this girl! Does she?"
+	result := "+HVXA7n2iUln6vXL2buTcfv28+am206YJuzC"
+
+	crypter := NewAesCtr(key, iv)
+	for i := 0; i < 2; i++ {
+		enc, err := crypter.Crypt([]byte(data))
+		if err != nil {
+			t.Error("crypt:", err)
+		}
+		if str := base64.StdEncoding.EncodeToString(enc); str != result {
+			t.Error("crypt result is wrong at call", i, ":", str)
+		}
+	}
+}
+
+func TestAesKeyStream(t *testing.T) {
+	key := []byte("11112222333344445555666677778888")
+	iv := []byte("1234567812345678")
+	data := []byte("I love this girl! Does she?")
+
+	crypter := NewAesCtr(key, iv)
+	whole, _ := crypter.Crypt(data)
+	stream := crypter.NewStream()
+	part1, err := stream.Crypt(data[:5])
+	if err != nil {
+		t.Fatal("crypt:", err)
+	}
+	part2, err := stream.Crypt(data[5:])
+	if err != nil {
+		t.Fatal("crypt:", err)
+	}
+	if enc := append(part1, part2...); !bytes.Equal(enc, whole) {
+		t.Error("key stream result is wrong:", enc)
+	}
+	if err := stream.Reset(nil); err != nil {
+		t.Fatal("reset:", err)
+	}
+	enc, _ := stream.Crypt(data)
+	if !bytes.Equal(enc, whole) {
+		t.Error("key stream should restart from the iv after reset")
+	}
+}
+
 func BenchmarkAes256CbcEncrypt1000Bytes(b *testing.B) {
 	b.StopTimer()
 	key := bytes.Repeat([]byte("a"), Aes256KeySize)
@@ -1 +1,3 @@
 module github.com/garvenc/go-crypt
+
+go 1.26
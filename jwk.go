@@ -0,0 +1,254 @@
+package crypt
+
+import (
+	"crypto"
+	"crypto/aes"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+)
+
+const (
+	JwkKtyRsa = "RSA" // The key type of RSA keys.
+	JwkKtyOct = "oct" // The key type of symmetric keys.
+)
+
+var (
+	errJwkKtyWrong        = errors.New("jwk kty wrong")
+	errJwkMemberIllegal   = errors.New("jwk member illegal")
+	errJwkMemberMissing   = errors.New("jwk member missing")
+	errJwkThumbprintHash  = errors.New("jwk thumbprint hash unavailable")
+	errJwkPublicExponent  = errors.New("jwk public exponent too large")
+	errJwkPrivateKeyWrong = errors.New("jwk private key inconsistent")
+)
+
+// JSON Web Key of RFC 7517, with the members of RSA keys and symmetric keys of RFC 7518.
+// All the key members are base64url encoded without padding.
+type Jwk struct {
+	Kty    string   `json:"kty"`
+	Use    string   `json:"use,omitempty"`
+	KeyOps []string `json:"key_ops,omitempty"`
+	Alg    string   `json:"alg,omitempty"`
+	Kid    string   `json:"kid,omitempty"`
+
+	// RSA public key.
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+
+	// RSA private key.
+	D   string          `json:"d,omitempty"`
+	P   string          `json:"p,omitempty"`
+	Q   string          `json:"q,omitempty"`
+	Dp  string          `json:"dp,omitempty"`
+	Dq  string          `json:"dq,omitempty"`
+	Qi  string          `json:"qi,omitempty"`
+	Oth []JwkOtherPrime `json:"oth,omitempty"`
+
+	// Symmetric key.
+	K string `json:"k,omitempty"`
+}
+
+// The third and subsequent primes of a multi-prime RSA private key.
+type JwkOtherPrime struct {
+	R string `json:"r"`
+	D string `json:"d"`
+	T string `json:"t"`
+}
+
+// JSON Web Key Set of RFC 7517.
+type JwkSet struct {
+	Keys []Jwk `json:"keys"`
+}
+
+// Return the first key whose kid equals to the kid param.
+func (s JwkSet) Lookup(kid string) (Jwk, bool) {
+	for _, key := range s.Keys {
+		if key.Kid == kid {
+			return key, true
+		}
+	}
+	return Jwk{}, false
+}
+
+// Return the "RSA" JWK of the public key. The kid and the other optional members are not set.
+func (pub *RsaPublic) ToJwk() (Jwk, error) {
+	if pub.err != nil {
+		return Jwk{}, pub.err
+	}
+	return Jwk{
+		Kty: JwkKtyRsa,
+		N:   encodeJwkInt(pub.pubKey.N),
+		E:   encodeJwkInt(big.NewInt(int64(pub.pubKey.E))),
+	}, nil
+}
+
+// Return the "RSA" JWK of the private key, including the CRT members.
+// The kid and the other optional members are not set.
+func (p RsaPrivate) ToJwk() (Jwk, error) {
+	if p.err != nil {
+		return Jwk{}, p.err
+	}
+	key := p.priKey
+	pub := p.Public()
+	jwk, err := pub.ToJwk()
+	if err != nil {
+		return Jwk{}, err
+	}
+	jwk.D = encodeJwkInt(key.D)
+	p1, q1 := key.Primes[0], key.Primes[1]
+	jwk.P = encodeJwkInt(p1)
+	jwk.Q = encodeJwkInt(q1)
+	jwk.Dp = encodeJwkInt(new(big.Int).Mod(key.D, new(big.Int).Sub(p1, bigOne)))
+	jwk.Dq = encodeJwkInt(new(big.Int).Mod(key.D, new(big.Int).Sub(q1, bigOne)))
+	jwk.Qi = encodeJwkInt(new(big.Int).ModInverse(q1, p1))
+	product := new(big.Int).Mul(p1, q1)
+	for _, r := range key.Primes[2:] {
+		jwk.Oth = append(jwk.Oth, JwkOtherPrime{
+			R: encodeJwkInt(r),
+			D: encodeJwkInt(new(big.Int).Mod(key.D, new(big.Int).Sub(r, bigOne))),
+			T: encodeJwkInt(new(big.Int).ModInverse(product, r)),
+		})
+		product.Mul(product, r)
+	}
+	return jwk, nil
+}
+
+// Return the "oct" JWK of an AES key.
+// The key must be either 16, 24, or 32 bytes to select AES-128, AES-192, or AES-256.
+func AesKeyToJwk(key []byte) (Jwk, error) {
+	if _, err := aes.NewCipher(key); err != nil {
+		return Jwk{}, err
+	}
+	return Jwk{
+		Kty: JwkKtyOct,
+		K:   base64.RawURLEncoding.EncodeToString(key),
+	}, nil
+}
+
+// Create RsaPublic from a "RSA" JWK. The private members are ignored.
+// The bit size of N must not be less than RsaDefaultMinPublicKeyBits.
+func RsaPublicFromJwk(jwk Jwk) (RsaPublic, error) {
+	return RsaPublicFromJwkWithMinBits(jwk, RsaDefaultMinPublicKeyBits)
+}
+
+// The same as RsaPublicFromJwk, except that the minimum bit size of N is minBits,
+// such as 2048 to reject the weak keys in production.
+func RsaPublicFromJwkWithMinBits(jwk Jwk, minBits int) (RsaPublic, error) {
+	if jwk.Kty != JwkKtyRsa {
+		return RsaPublic{err: errJwkKtyWrong}, errJwkKtyWrong
+	}
+	n, err := decodeJwkInt(jwk.N)
+	if err != nil {
+		return RsaPublic{err: err}, err
+	}
+	e, err := decodeJwkInt(jwk.E)
+	if err != nil {
+		return RsaPublic{err: err}, err
+	}
+	if !e.IsInt64() || e.Int64() > RsaMaxPublicExponent {
+		return RsaPublic{err: errJwkPublicExponent}, errJwkPublicExponent
+	}
+	pub := newRsaPublic(&rsa.PublicKey{
+		N: n,
+		E: int(e.Int64()),
+	}, minBits)
+	return pub, pub.err
+}
+
+// Create RsaPrivate from a "RSA" JWK which has the private members.
+// The "d", "p" and "q" members are required, the CRT members are recomputed.
+func RsaPrivateFromJwk(jwk Jwk) (RsaPrivate, error) {
+	pub, err := RsaPublicFromJwk(jwk)
+	if err != nil {
+		return RsaPrivate{err: err}, err
+	}
+	key := &rsa.PrivateKey{
+		PublicKey: *pub.pubKey,
+	}
+	members := []string{jwk.D, jwk.P, jwk.Q}
+	for _, oth := range jwk.Oth {
+		members = append(members, oth.R)
+	}
+	ints := make([]*big.Int, len(members))
+	for i, member := range members {
+		if ints[i], err = decodeJwkInt(member); err != nil {
+			return RsaPrivate{err: err}, err
+		}
+	}
+	key.D = ints[0]
+	key.Primes = ints[1:]
+	if err := key.Validate(); err != nil {
+		return RsaPrivate{err: errJwkPrivateKeyWrong}, errJwkPrivateKeyWrong
+	}
+	key.Precompute()
+	return RsaPrivate{priKey: key}, nil
+}
+
+// Return the AES key of an "oct" JWK.
+func AesKeyFromJwk(jwk Jwk) ([]byte, error) {
+	if jwk.Kty != JwkKtyOct {
+		return nil, errJwkKtyWrong
+	}
+	key, err := base64.RawURLEncoding.DecodeString(jwk.K)
+	if err != nil {
+		return nil, errJwkMemberIllegal
+	}
+	if _, err := aes.NewCipher(key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// JWK thumbprint of RFC 7638. It is the hash of the required members in lexicographic order.
+//
+// hash:
+// Usually crypto.SHA256. It must be linked into the binary.
+func (j Jwk) Thumbprint(hash crypto.Hash) ([]byte, error) {
+	if hash == 0 || !hash.Available() {
+		return nil, errJwkThumbprintHash
+	}
+	var members []string // Pairs of name and value in lexicographic order.
+	switch j.Kty {
+	case JwkKtyRsa:
+		members = []string{"e", j.E, "kty", j.Kty, "n", j.N}
+	case JwkKtyOct:
+		members = []string{"k", j.K, "kty", j.Kty}
+	default:
+		return nil, errJwkKtyWrong
+	}
+	buf := []byte{'{'}
+	for i := 0; i < len(members); i += 2 {
+		if members[i+1] == "" {
+			return nil, errJwkMemberMissing
+		}
+		if i > 0 {
+			buf = append(buf, ',')
+		}
+		name, _ := json.Marshal(members[i])
+		value, _ := json.Marshal(members[i+1])
+		buf = append(buf, name...)
+		buf = append(buf, ':')
+		buf = append(buf, value...)
+	}
+	buf = append(buf, '}')
+	h := hash.New()
+	h.Write(buf)
+	return h.Sum(nil), nil
+}
+
+func encodeJwkInt(i *big.Int) string {
+	return base64.RawURLEncoding.EncodeToString(i.Bytes())
+}
+
+func decodeJwkInt(s string) (*big.Int, error) {
+	if s == "" {
+		return nil, errJwkMemberMissing
+	}
+	buf, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, errJwkMemberIllegal
+	}
+	return new(big.Int).SetBytes(buf), nil
+}
@@ -0,0 +1,125 @@
+package crypt
+
+import (
+	"bytes"
+	"crypto"
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+)
+
+func TestJwkThumbprint(t *testing.T) {
+	// The example of 3.1 of RFC 7638.
+	jwk := Jwk{
+		Kty: JwkKtyRsa,
+		N: "0vx7agoebGcQSuuPiLJXZptN9nndrQmbXEps2aiAFbWhM78LhWx4cbbfAAtVT86zwu1RK7aPFFxuhDR1L6tSoc_BJECP" +
+			"ebWKRXjBZCiFV4n3oknjhMstn64tZ_2W-5JsGY4Hc5n9yBXArwl93lqt7_RN5w6Cf0h4QyQ5v-65YGjQR0_FDW2QvzqY" +
+			"368QQMicAtaSqzs8KJZgnYb9c7d0zgdAZHzu6qMQvRL5hajrn1n91CbOpbISD08qNLyrdkt-bFTWhAI4vMQFh6WeZu0f" +
+			"M4lFd2NcRwr3XPksINHaQ-G_xBniIqbw0Ls1jF44-csFCur-kEgU8awapJzKnqDKgw",
+		E:   "AQAB",
+		Alg: "RS256",
+		Kid: "2011-04-29",
+	}
+	result := "NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs"
+
+	thumbprint, err := jwk.Thumbprint(crypto.SHA256)
+	if err != nil {
+		t.Fatal("thumbprint err:", err)
+	}
+	if str := base64.RawURLEncoding.EncodeToString(thumbprint); str != result {
+		t.Error("thumbprint result is wrong:", str)
+	}
+}
+
+func TestRsaJwk(t *testing.T) {
+	pri := NewRsaPrivate(1024, 65537)
+	input := []byte("I love this girl! Does she?")
+	enc, _ := pri.PublicEncryptPkcs1v15(input)
+
+	priJwk, err := pri.ToJwk()
+	if err != nil {
+		t.Fatal("private to jwk err:", err)
+	}
+	buf, err := json.Marshal(priJwk)
+	if err != nil {
+		t.Fatal("marshal err:", err)
+	}
+	var parsedJwk Jwk
+	if err := json.Unmarshal(buf, &parsedJwk); err != nil {
+		t.Fatal("unmarshal err:", err)
+	}
+	parsed, err := RsaPrivateFromJwk(parsedJwk)
+	if err != nil {
+		t.Fatal("private from jwk err:", err)
+	}
+	dec, err := parsed.PrivateDecryptPkcs1v15(enc)
+	if err != nil || !bytes.Equal(dec, input) {
+		t.Error("the parsed private key can not decrypt:", err)
+	}
+
+	pub, err := RsaPublicFromJwk(parsedJwk)
+	if err != nil {
+		t.Fatal("public from jwk err:", err)
+	}
+	pubJwk, err := pub.ToJwk()
+	if err != nil {
+		t.Fatal("public to jwk err:", err)
+	}
+	if !bytes.Equal(pub.pubKey.N.Bytes(), pri.GetNBytes()) || pubJwk.E != "AQAB" {
+		t.Error("the parsed public key is wrong")
+	}
+
+	parsedJwk.D = parsedJwk.P
+	if _, err := RsaPrivateFromJwk(parsedJwk); err == nil {
+		t.Error("inconsistent private jwk should have error")
+	}
+}
+
+func TestRsaPublicFromJwkWithMinBits(t *testing.T) {
+	pub := NewRsaPrivate(1024, 65537).Public()
+	jwk, err := pub.ToJwk()
+	if err != nil {
+		t.Fatal("public to jwk err:", err)
+	}
+	if _, err := RsaPublicFromJwkWithMinBits(jwk, 1024); err != nil {
+		t.Error("public from jwk err:", err)
+	}
+	if _, err := RsaPublicFromJwkWithMinBits(jwk, 2048); err != errRsaPublicKeyTooSmall {
+		t.Error("public from jwk with min bits 2048 err:", err)
+	}
+}
+
+func TestAesJwk(t *testing.T) {
+	key := []byte("11112222333344445555666677778888")
+	jwk, err := AesKeyToJwk(key)
+	if err != nil {
+		t.Fatal("to jwk err:", err)
+	}
+	if jwk.Kty != JwkKtyOct || jwk.K != "MTExMTIyMjIzMzMzNDQ0NDU1NTU2NjY2Nzc3Nzg4ODg" {
+		t.Error("jwk is wrong:", jwk)
+	}
+	parsed, err := AesKeyFromJwk(jwk)
+	if err != nil {
+		t.Fatal("from jwk err:", err)
+	}
+	if !bytes.Equal(parsed, key) {
+		t.Error("parsed key is wrong:", parsed)
+	}
+	if _, err := RsaPublicFromJwk(jwk); err == nil {
+		t.Error("oct jwk as rsa key should have error")
+	}
+}
+
+func TestJwkSetLookup(t *testing.T) {
+	data := `{"keys":[{"kty":"oct","kid":"a","k":"MTExMTIyMjIzMzMzNDQ0NA"},{"kty":"RSA","kid":"b","n":"AQAB","e":"AQAB"}]}`
+	var set JwkSet
+	if err := json.Unmarshal([]byte(data), &set); err != nil {
+		t.Fatal("unmarshal err:", err)
+	}
+	if jwk, ok := set.Lookup("b"); !ok || jwk.Kty != JwkKtyRsa {
+		t.Error("lookup b is wrong:", jwk, ok)
+	}
+	if _, ok := set.Lookup("c"); ok {
+		t.Error("lookup c should not be found")
+	}
+}
@@ -1,6 +1,8 @@
 package crypt
 
 import (
+	"crypto/rand"
+	"crypto/subtle"
 	"errors"
 )
 
@@ -51,21 +53,21 @@ func (p Pkcs7Padding) Unpad(buf []byte) ([]byte, error) {
 	return buf[:len(buf)-paddingSize], nil
 }
 
+// It is constant time with respect to the content of buf, to avoid the padding oracle attack.
+// The length of buf must be a positive multiple of the block size,
+// and the padding size must be in [1, blockSize]. Any malformed padding returns the same error.
 func (p Pkcs7Padding) paddingSizeOfUnpad(buf []byte) (int, error) {
-	length := len(buf)
-	if length <= 0 {
-		return 0, errPaddingIsWrong
+	if err := checkUnpadSize(p.blockSize, len(buf)); err != nil {
+		return 0, err
 	}
-	value := buf[length-1]
+	block := buf[len(buf)-p.blockSize:]
+	value := block[p.blockSize-1]
 	paddingSize := int(value)
-	if paddingSize > length {
+	good := constantTimePaddingSizeIsLegal(paddingSize, p.blockSize) &
+		constantTimeTailIs(block, paddingSize, value)
+	if good != 1 {
 		return 0, errPaddingIsWrong
 	}
-	for i := 2; i <= paddingSize; i++ { // The src[length-1] is already value.
-		if buf[length-i] != value {
-			return 0, errPaddingIsWrong
-		}
-	}
 	return paddingSize, nil
 }
 
@@ -91,3 +93,219 @@ func (p Pkcs5Padding) Pad(buf []byte) []byte {
 func (p Pkcs5Padding) Unpad(buf []byte) ([]byte, error) {
 	return p.pkcs7.Unpad(buf)
 }
+
+// ANSI X9.23 padding: Zeros followed by a byte of the padding size.
+type AnsiX923Padding struct {
+	blockSize int
+}
+
+// blockSize: It is the size of bytes in a block.
+func NewAnsiX923Padding(blockSize int) AnsiX923Padding {
+	return AnsiX923Padding{
+		blockSize: blockSize,
+	}
+}
+
+func (p AnsiX923Padding) BlockSize() int {
+	return p.blockSize
+}
+
+func (p AnsiX923Padding) Pad(buf []byte) []byte {
+	paddingSize := p.blockSize - len(buf)%p.blockSize
+	result := make([]byte, len(buf)+paddingSize)
+	copy(result, buf)
+	result[len(result)-1] = byte(paddingSize)
+	return result
+}
+
+// It is constant time with respect to the content of buf, like Pkcs7Padding.
+func (p AnsiX923Padding) Unpad(buf []byte) ([]byte, error) {
+	if err := checkUnpadSize(p.blockSize, len(buf)); err != nil {
+		return nil, err
+	}
+	block := buf[len(buf)-p.blockSize:]
+	paddingSize := int(block[p.blockSize-1])
+	legal := constantTimePaddingSizeIsLegal(paddingSize, p.blockSize)
+	// The last byte is not zero if the size is legal, so only check the zeros before it.
+	zerosSize := subtle.ConstantTimeSelect(legal, paddingSize-1, 0)
+	if legal&constantTimeTailIs(block[:p.blockSize-1], zerosSize, 0) != 1 {
+		return nil, errPaddingIsWrong
+	}
+	return buf[:len(buf)-paddingSize], nil
+}
+
+// ISO 10126 padding: Random bytes followed by a byte of the padding size.
+// The random bytes are not checked by Unpad.
+type Iso10126Padding struct {
+	blockSize int
+}
+
+// blockSize: It is the size of bytes in a block.
+func NewIso10126Padding(blockSize int) Iso10126Padding {
+	return Iso10126Padding{
+		blockSize: blockSize,
+	}
+}
+
+func (p Iso10126Padding) BlockSize() int {
+	return p.blockSize
+}
+
+func (p Iso10126Padding) Pad(buf []byte) []byte {
+	paddingSize := p.blockSize - len(buf)%p.blockSize
+	result := make([]byte, len(buf)+paddingSize)
+	copy(result, buf)
+	rand.Read(result[len(buf) : len(result)-1])
+	result[len(result)-1] = byte(paddingSize)
+	return result
+}
+
+func (p Iso10126Padding) Unpad(buf []byte) ([]byte, error) {
+	if err := checkUnpadSize(p.blockSize, len(buf)); err != nil {
+		return nil, err
+	}
+	paddingSize := int(buf[len(buf)-1])
+	if constantTimePaddingSizeIsLegal(paddingSize, p.blockSize) != 1 {
+		return nil, errPaddingIsWrong
+	}
+	return buf[:len(buf)-paddingSize], nil
+}
+
+// ISO/IEC 7816-4 padding: A byte of 0x80 followed by zeros.
+type Iso7816Padding struct {
+	blockSize int
+}
+
+// blockSize: It is the size of bytes in a block.
+func NewIso7816Padding(blockSize int) Iso7816Padding {
+	return Iso7816Padding{
+		blockSize: blockSize,
+	}
+}
+
+func (p Iso7816Padding) BlockSize() int {
+	return p.blockSize
+}
+
+func (p Iso7816Padding) Pad(buf []byte) []byte {
+	paddingSize := p.blockSize - len(buf)%p.blockSize
+	result := make([]byte, len(buf)+paddingSize)
+	copy(result, buf)
+	result[len(buf)] = 0x80
+	return result
+}
+
+// It is constant time with respect to the content of buf, like Pkcs7Padding.
+// The last non-zero byte of the last block must be 0x80.
+func (p Iso7816Padding) Unpad(buf []byte) ([]byte, error) {
+	if err := checkUnpadSize(p.blockSize, len(buf)); err != nil {
+		return nil, err
+	}
+	block := buf[len(buf)-p.blockSize:]
+	good, found, paddingSize := 1, 0, 0
+	for i := 1; i <= p.blockSize; i++ {
+		b := block[p.blockSize-i]
+		// Whether b is the first non-zero byte from the end.
+		isFirst := (1 - found) & (1 - subtle.ConstantTimeByteEq(b, 0))
+		good &= subtle.ConstantTimeSelect(isFirst, subtle.ConstantTimeByteEq(b, 0x80), 1)
+		paddingSize = subtle.ConstantTimeSelect(isFirst, i, paddingSize)
+		found |= isFirst
+	}
+	if good&found != 1 {
+		return nil, errPaddingIsWrong
+	}
+	return buf[:len(buf)-paddingSize], nil
+}
+
+// Zero padding: Zeros to fill the last block. Nothing is added if the size is already multiple of block size,
+// except that empty data is padded to a block of zeros, so that the result is never empty.
+// Unpad removes the trailing zeros of the last block, so the data must not end with zero.
+type ZeroPadding struct {
+	blockSize int
+}
+
+// blockSize: It is the size of bytes in a block.
+func NewZeroPadding(blockSize int) ZeroPadding {
+	return ZeroPadding{
+		blockSize: blockSize,
+	}
+}
+
+func (p ZeroPadding) BlockSize() int {
+	return p.blockSize
+}
+
+func (p ZeroPadding) Pad(buf []byte) []byte {
+	paddingSize := (p.blockSize - len(buf)%p.blockSize) % p.blockSize
+	if len(buf) == 0 {
+		paddingSize = p.blockSize
+	}
+	result := make([]byte, len(buf)+paddingSize)
+	copy(result, buf)
+	return result
+}
+
+// Remove the trailing zeros of the last block, which are at most blockSize for empty data.
+func (p ZeroPadding) Unpad(buf []byte) ([]byte, error) {
+	if err := checkUnpadSize(p.blockSize, len(buf)); err != nil {
+		return nil, err
+	}
+	length := len(buf)
+	for i := 0; i < p.blockSize && buf[length-1] == 0; i++ {
+		length--
+	}
+	return buf[:length], nil
+}
+
+// No padding: The data must be multiple of block size.
+// The block mode encrypter returns an error if it is not.
+type NoPadding struct {
+	blockSize int
+}
+
+// blockSize: It is the size of bytes in a block.
+func NewNoPadding(blockSize int) NoPadding {
+	return NoPadding{
+		blockSize: blockSize,
+	}
+}
+
+func (p NoPadding) BlockSize() int {
+	return p.blockSize
+}
+
+func (p NoPadding) Pad(buf []byte) []byte {
+	return append([]byte(nil), buf...)
+}
+
+func (p NoPadding) Unpad(buf []byte) ([]byte, error) {
+	if p.blockSize <= 0 || len(buf)%p.blockSize != 0 {
+		return nil, errPaddingIsWrong
+	}
+	return buf, nil
+}
+
+// The length of buf must be a positive multiple of the block size, which must be in [1, 255].
+func checkUnpadSize(blockSize, length int) error {
+	if blockSize <= 0 || blockSize > 255 || length <= 0 || length%blockSize != 0 {
+		return errPaddingIsWrong
+	}
+	return nil
+}
+
+// Return 1 if paddingSize is in [1, blockSize], otherwise 0.
+func constantTimePaddingSizeIsLegal(paddingSize, blockSize int) int {
+	return subtle.ConstantTimeLessOrEq(1, paddingSize) & subtle.ConstantTimeLessOrEq(paddingSize, blockSize)
+}
+
+// Return 1 if the last size bytes of block are all value, otherwise 0.
+// All bytes of block are read, so that it is constant time with respect to the content of block and size.
+func constantTimeTailIs(block []byte, size int, value byte) int {
+	good := 1
+	for i := 1; i <= len(block); i++ {
+		inTail := subtle.ConstantTimeLessOrEq(i, size)
+		isValue := subtle.ConstantTimeByteEq(block[len(block)-i], value)
+		good &= subtle.ConstantTimeSelect(inTail, isValue, 1)
+	}
+	return good
+}
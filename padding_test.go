@@ -32,6 +32,67 @@ func TestPkcs7Padding(t *testing.T) {
 	})
 }
 
+func TestPkcs7PaddingUnpadStrict(t *testing.T) {
+	for _, blockSize := range []int{5, 8, 16} {
+		padding := NewPkcs7Padding(blockSize)
+		for _, data := range [][]byte{nil, bytes.Repeat([]byte{1}, blockSize-1), bytes.Repeat([]byte{2}, blockSize*2+3)} {
+			result, err := padding.Unpad(padding.Pad(data))
+			if err != nil || !bytes.Equal(result, data) {
+				t.Error("unpad the padded err:", blockSize, len(data), err)
+			}
+		}
+
+		// Every value of the last byte, with the bytes before it all the same value.
+		for value := 0; value < 256; value++ {
+			buf := bytes.Repeat([]byte{byte(value)}, blockSize*2)
+			result, err := padding.Unpad(buf)
+			if value >= 1 && value <= blockSize {
+				if err != nil || len(result) != len(buf)-value {
+					t.Error("unpad legal padding err:", blockSize, value, err)
+				}
+			} else if err != errPaddingIsWrong {
+				t.Error("unpad illegal padding size err:", blockSize, value, err)
+			}
+		}
+
+		// Every padding size, with every byte of the padding wrong.
+		for paddingSize := 1; paddingSize <= blockSize; paddingSize++ {
+			for i := 1; i < paddingSize; i++ {
+				buf := bytes.Repeat([]byte{byte(paddingSize)}, blockSize)
+				buf[blockSize-1-i] ^= 0x80
+				if _, err := padding.Unpad(buf); err != errPaddingIsWrong {
+					t.Error("unpad wrong padding byte err:", blockSize, paddingSize, i, err)
+				}
+			}
+			// The byte just before the padding is not checked.
+			buf := bytes.Repeat([]byte{byte(paddingSize)}, blockSize)
+			if paddingSize < blockSize {
+				buf[blockSize-1-paddingSize] ^= 0x80
+			}
+			if _, err := padding.Unpad(buf); err != nil {
+				t.Error("unpad with other data before padding err:", blockSize, paddingSize, err)
+			}
+		}
+
+		// The illegal lengths.
+		for _, length := range []int{0, 1, blockSize - 1, blockSize + 1, blockSize*2 - 1} {
+			buf := bytes.Repeat([]byte{1}, length)
+			if _, err := padding.Unpad(buf); err != errPaddingIsWrong {
+				t.Error("unpad illegal length err:", blockSize, length, err)
+			}
+		}
+	}
+
+	for _, blockSize := range []int{0, -1, 256} {
+		if _, err := NewPkcs7Padding(blockSize).Unpad([]byte{1}); err != errPaddingIsWrong {
+			t.Error("unpad with illegal block size err:", blockSize, err)
+		}
+	}
+	if _, err := NewPkcs5Padding().Unpad(bytes.Repeat([]byte{9}, 16)); err != errPaddingIsWrong {
+		t.Error("pkcs5 unpad padding size greater than 8 err:", err)
+	}
+}
+
 func BenchmarkPkcs7PaddingPad(b *testing.B) {
 	b.StopTimer()
 	blockSize := 16 // aes block size
@@ -54,3 +115,130 @@ func BenchmarkPkcs7PaddingUnpad(b *testing.B) {
 		padding.Unpad(bufPad)
 	}
 }
+
+func TestOtherPaddings(t *testing.T) {
+	data := []byte{0xdd, 0xdd, 0xdd, 0xdd}
+	cases := []struct {
+		name    string
+		padding Padding
+		padded  []byte
+		illegal [][]byte
+	}{
+		{
+			name:    "AnsiX923",
+			padding: NewAnsiX923Padding(8),
+			padded:  []byte{0xdd, 0xdd, 0xdd, 0xdd, 0, 0, 0, 4},
+			illegal: [][]byte{
+				{0xdd, 0xdd, 0xdd, 0xdd, 0, 1, 0, 4},
+				{0xdd, 0xdd, 0xdd, 0xdd, 0, 0, 0, 0},
+				{0xdd, 0xdd, 0xdd, 0xdd, 0, 0, 0, 9},
+			},
+		},
+		{
+			name:    "Iso10126",
+			padding: NewIso10126Padding(8),
+			padded:  []byte{0xdd, 0xdd, 0xdd, 0xdd, 0x81, 0xa6, 0x23, 4},
+			illegal: [][]byte{
+				{0xdd, 0xdd, 0xdd, 0xdd, 0x81, 0xa6, 0x23, 0},
+				{0xdd, 0xdd, 0xdd, 0xdd, 0x81, 0xa6, 0x23, 9},
+			},
+		},
+		{
+			name:    "Iso7816",
+			padding: NewIso7816Padding(8),
+			padded:  []byte{0xdd, 0xdd, 0xdd, 0xdd, 0x80, 0, 0, 0},
+			illegal: [][]byte{
+				{0xdd, 0xdd, 0xdd, 0xdd, 0x80, 0, 1, 0},
+				{0xdd, 0xdd, 0xdd, 0xdd, 0x81, 0, 0, 0},
+				{0, 0, 0, 0, 0, 0, 0, 0},
+			},
+		},
+		{
+			name:    "Zero",
+			padding: NewZeroPadding(8),
+			padded:  []byte{0xdd, 0xdd, 0xdd, 0xdd, 0, 0, 0, 0},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			result := c.padding.Pad(data)
+			if c.name == "Iso10126" {
+				if len(result) != len(c.padded) || !bytes.Equal(result[:4], data) || result[7] != 4 {
+					t.Error("Pad wrong result:", result)
+				}
+			} else if !bytes.Equal(result, c.padded) {
+				t.Error("Pad wrong result:", result)
+			}
+			result, err := c.padding.Unpad(c.padded)
+			if err != nil || !bytes.Equal(result, data) {
+				t.Error("Unpad wrong result:", result, err)
+			}
+			for _, buf := range c.illegal {
+				if _, err := c.padding.Unpad(buf); err != errPaddingIsWrong {
+					t.Error("Unpad illegal padding err:", buf, err)
+				}
+			}
+			if _, err := c.padding.Unpad(c.padded[:7]); err != errPaddingIsWrong {
+				t.Error("Unpad illegal length err:", err)
+			}
+		})
+	}
+}
+
+func TestZeroPaddingEmpty(t *testing.T) {
+	padding := NewZeroPadding(8)
+	padded := padding.Pad(nil)
+	if !bytes.Equal(padded, make([]byte, 8)) {
+		t.Error("Pad empty data wrong result:", padded)
+	}
+	if result, err := padding.Unpad(padded); err != nil || len(result) != 0 {
+		t.Error("Unpad a block of zeros wrong result:", result, err)
+	}
+	for _, buf := range [][]byte{nil, {}, {0xdd, 0, 0}} {
+		if _, err := padding.Unpad(buf); err != errPaddingIsWrong {
+			t.Error("Unpad illegal length err:", buf, err)
+		}
+	}
+}
+
+func TestPaddingsWithAesCbc(t *testing.T) {
+	key := bytes.Repeat([]byte{1}, Aes128KeySize)
+	iv := bytes.Repeat([]byte{2}, AesIvSize)
+	paddings := []Padding{
+		NewPkcs7Padding(AesBlockSize),
+		NewAnsiX923Padding(AesBlockSize),
+		NewIso10126Padding(AesBlockSize),
+		NewIso7816Padding(AesBlockSize),
+		NewZeroPadding(AesBlockSize),
+	}
+	for _, padding := range paddings {
+		for size := 0; size <= AesBlockSize*2+1; size++ {
+			data := bytes.Repeat([]byte{0xdd}, size)
+			enc, err := NewAesCbcEncrypter(key, iv, padding).Encrypt(data)
+			if err != nil {
+				t.Fatal("encrypt err:", padding, size, err)
+			}
+			dec, err := NewAesCbcDecrypter(key, iv, padding).Decrypt(enc)
+			if err != nil || !bytes.Equal(dec, data) {
+				t.Error("decrypt err:", padding, size, err)
+			}
+		}
+	}
+
+	padding := NewNoPadding(AesBlockSize)
+	data := bytes.Repeat([]byte{0}, AesBlockSize*2)
+	enc, err := NewAesCbcEncrypter(key, iv, padding).Encrypt(data)
+	if err != nil || len(enc) != len(data) {
+		t.Fatal("encrypt without padding err:", err)
+	}
+	dec, err := NewAesCbcDecrypter(key, iv, padding).Decrypt(enc)
+	if err != nil || !bytes.Equal(dec, data) {
+		t.Error("decrypt without padding err:", err)
+	}
+	if _, err := NewAesCbcEncrypter(key, iv, padding).Encrypt(data[1:]); err != errAesDataSizeMustBeMultipleOfBlockSize {
+		t.Error("encrypt unaligned data without padding err:", err)
+	}
+	if _, err := NewAesCbcEncrypter(key, iv, padding).NewStream().EncryptFinal(data[1:]); err != errAesDataSizeMustBeMultipleOfBlockSize {
+		t.Error("encrypt final unaligned data without padding err:", err)
+	}
+}
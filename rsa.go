@@ -2,14 +2,38 @@ package crypt
 
 import (
 	"bytes"
+	"context"
+	"crypto"
 	"crypto/rand"
 	"crypto/rsa"
+	_ "crypto/sha1"   // Register crypto.SHA1.
+	_ "crypto/sha256" // Register crypto.SHA256.
+	_ "crypto/sha512" // Register crypto.SHA384 and crypto.SHA512.
 	"errors"
 	"io"
 	"math/big"
+	"runtime"
+	"sync"
+	"sync/atomic"
 )
 
-var errRsaPublicKeySize = errors.New("rsa public key size illegal")
+const (
+	// The default minimum bit size of N accepted by NewRsaPublic.
+	RsaDefaultMinPublicKeyBits = 1024
+	// The maximum public exponent E accepted by crypto/rsa.
+	RsaMaxPublicExponent = 1<<31 - 1
+)
+
+var (
+	errRsaPublicKeySize        = errors.New("rsa public key size illegal")
+	errRsaPublicKeyTooSmall    = errors.New("rsa public key size smaller than minimum")
+	errRsaPublicExponent       = errors.New("rsa public exponent illegal")
+	errRsaModulusIllegal       = errors.New("rsa modulus illegal")
+	errRsaOaepHashUnsupported  = errors.New("rsa oaep hash unsupported")
+	errRsaPrivateKeyIncomplete = errors.New("rsa private key incomplete")
+	errRsaCiphertextIllegal    = errors.New("rsa ciphertext illegal")
+	errRsaPkcs1v15Type1Padding = errors.New("rsa pkcs#1 v1.5 type 1 padding is wrong")
+)
 
 // This is copy from crypto/rsa.
 var bigOne = big.NewInt(1)
@@ -25,7 +49,7 @@ type RsaPrivate struct {
 // The byte size of N equals to bits/8. 1024 usually, 2048 in some important cases.
 //
 // e:
-// The public exponent E value. Usually use 65537.
+// The public exponent E value. Usually use 65537. It must be odd and greater than 1.
 //
 // Can call HasError to see if it has an error.
 func NewRsaPrivate(bits, e int) RsaPrivate {
@@ -34,7 +58,11 @@ func NewRsaPrivate(bits, e int) RsaPrivate {
 		pri.err = errRsaPublicKeySize
 		return pri
 	}
-	key, err := generateMultiPrimeKey(rand.Reader, 2, bits, e) // Call it like what in crypto/rsa.GenerateKey.
+	if err := checkRsaPublicExponent(e); err != nil {
+		pri.err = err
+		return pri
+	}
+	key, err := generateMultiPrimeKey(context.Background(), rand.Reader, 2, bits, e) // Call it like what in crypto/rsa.GenerateKey.
 	if err != nil {
 		pri.err = err
 		return pri
@@ -44,10 +72,148 @@ func NewRsaPrivate(bits, e int) RsaPrivate {
 	return pri
 }
 
+// Generate a new key like NewRsaPrivate, but it can be cancelled and returns the error directly.
+//
+// ctx:
+// The generation stops with ctx.Err() as soon as ctx is done. It is checked between prime candidates.
+//
+// random:
+// The source of randomness. If it is nil, crypto/rand.Reader is used.
+// Pass a deterministic reader only in tests, the key is as secret as the random bytes.
+//
+// bits, e:
+// The same as NewRsaPrivate.
+//
+// nprimes:
+// The number of primes. If it is 0, 2 is used. More primes make the private operations faster
+// but are less interoperable. Only use 2 unless knowing what it means.
+func GenerateRsaPrivate(ctx context.Context, random io.Reader, bits, e, nprimes int) (RsaPrivate, error) {
+	if random == nil {
+		random = rand.Reader
+	}
+	if nprimes == 0 {
+		nprimes = 2
+	}
+	if bits%8 != 0 || bits < 16*nprimes {
+		return RsaPrivate{err: errRsaPublicKeySize}, errRsaPublicKeySize
+	}
+	if err := checkRsaPublicExponent(e); err != nil {
+		return RsaPrivate{err: err}, err
+	}
+	key, err := generateMultiPrimeKey(ctx, random, nprimes, bits, e)
+	if err != nil {
+		return RsaPrivate{err: err}, err
+	}
+	return RsaPrivate{priKey: key}, nil
+}
+
+// n:
+// The bytes of N. The size of N is the length of the public modulus.
+//
+// e:
+// The public exponent E value.
+//
+// d:
+// The bytes of the private exponent D.
+//
+// primes:
+// The bytes of the prime factors of N. There are at least 2 primes.
+//
+// The key is validated, and the values for CRT are precomputed.
+//
+// Can call HasError to see if it has an error.
+func NewRsaPrivateFromComponents(n []byte, e int, d []byte, primes [][]byte) RsaPrivate {
+	key := &rsa.PrivateKey{
+		PublicKey: rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: e,
+		},
+		D: new(big.Int).SetBytes(d),
+	}
+	for _, prime := range primes {
+		key.Primes = append(key.Primes, new(big.Int).SetBytes(prime))
+	}
+	return NewRsaPrivateFromKey(key)
+}
+
+// key:
+// It is used directly but not copied, do not modify it later.
+//
+// The key is validated, and the values for CRT are precomputed if they are not.
+//
+// Can call HasError to see if it has an error.
+func NewRsaPrivateFromKey(key *rsa.PrivateKey) RsaPrivate {
+	pri := RsaPrivate{}
+	if key == nil || key.N == nil || key.D == nil {
+		pri.err = errRsaPrivateKeyIncomplete
+		return pri
+	}
+	if err := key.Validate(); err != nil {
+		pri.err = err
+		return pri
+	}
+	if key.Precomputed.Dp == nil {
+		key.Precompute()
+	}
+	pri.priKey = key
+	return pri
+}
+
 func (p RsaPrivate) HasError() (error, bool) {
 	return p.err, p.err != nil
 }
 
+// Check the key is consistent, such as N is the product of the primes and D is the inverse of E.
+func (p RsaPrivate) Validate() error {
+	if p.err != nil {
+		return p.err
+	}
+	return p.priKey.Validate()
+}
+
+// Return the public half of the key.
+// If p has error, the result has the same error.
+func (p RsaPrivate) Public() RsaPublic {
+	if p.err != nil {
+		return RsaPublic{err: p.err}
+	}
+	return RsaPublic{
+		pubKey: &rsa.PublicKey{
+			N: new(big.Int).Set(p.priKey.N),
+			E: p.priKey.E,
+		},
+	}
+}
+
+// The same as Public, except that the public half is validated like NewRsaPublicWithMinBits,
+// such as minBits 2048 to reject the weak keys parsed from files in production.
+// If p has error, the result has the same error.
+func (p RsaPrivate) PublicWithMinBits(minBits int) RsaPublic {
+	if p.err != nil {
+		return RsaPublic{err: p.err}
+	}
+	pub := p.Public()
+	return newRsaPublic(pub.pubKey, minBits)
+}
+
+// The bit size of N.
+// If p has error, return 0.
+func (p RsaPrivate) Bits() int {
+	if p.err != nil {
+		return 0
+	}
+	return p.priKey.N.BitLen()
+}
+
+// The byte size of N, which is also the size of each encrypted block and signature.
+// If p has error, return 0.
+func (p RsaPrivate) Size() int {
+	if p.err != nil {
+		return 0
+	}
+	return rsaModulusSize(&p.priKey.PublicKey)
+}
+
 // Public encrypt of PKCS#1 v1.5.
 // Each bits/8-11 or less bytes will be encrypted to bits/8 bytes.
 // If len(data) == 0, will return an empty buf too.
@@ -57,7 +223,18 @@ func (p RsaPrivate) PublicEncryptPkcs1v15(data []byte) ([]byte, error) {
 	if p.err != nil {
 		return nil, p.err
 	}
-	return publicEncryptPkcs1v15(&p.priKey.PublicKey, data)
+	return publicEncryptPkcs1v15(&p.priKey.PublicKey, data, 1)
+}
+
+// The same as PublicEncryptPkcs1v15, except that the blocks are encrypted by workers goroutines in parallel.
+// If workers is 0, runtime.GOMAXPROCS(0) is used.
+//
+// The result will not share the array of data.
+func (p RsaPrivate) PublicEncryptPkcs1v15Parallel(data []byte, workers int) ([]byte, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+	return publicEncryptPkcs1v15(&p.priKey.PublicKey, data, workers)
 }
 
 // Private Decrypt of PKCS#1 v1.5.
@@ -69,7 +246,58 @@ func (p RsaPrivate) PrivateDecryptPkcs1v15(data []byte) ([]byte, error) {
 	if p.err != nil {
 		return nil, p.err
 	}
-	return privateDecryptPkcs1v15(p.priKey, data)
+	return privateDecryptPkcs1v15(p.priKey, data, 1)
+}
+
+// The same as PrivateDecryptPkcs1v15, except that the blocks are decrypted by workers goroutines in parallel.
+// If workers is 0, runtime.GOMAXPROCS(0) is used.
+// It is much faster for the data of many blocks on a multi-core machine.
+//
+// The result will not share the array of data.
+func (p RsaPrivate) PrivateDecryptPkcs1v15Parallel(data []byte, workers int) ([]byte, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+	return privateDecryptPkcs1v15(p.priKey, data, workers)
+}
+
+// Private encrypt of PKCS#1 v1.5, with the type 1 padding.
+// Each bits/8-11 or less bytes will be encrypted to bits/8 bytes.
+// If len(data) == 0, will return an empty buf too.
+//
+// It is the same as openssl_private_encrypt of PHP and Cipher "RSA/ECB/PKCS1Padding" of Java with the private key.
+// It is only for the legacy protocols, use SignPkcs1v15 or SignPss to sign instead.
+//
+// The result will not share the array of data.
+func (p RsaPrivate) PrivateEncryptPkcs1v15(data []byte) ([]byte, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+	return privateEncryptPkcs1v15(p.priKey, data)
+}
+
+// Public encrypt of OAEP, the same as RsaPublic.PublicEncryptOaep.
+//
+// The result will not share the array of data.
+func (p RsaPrivate) PublicEncryptOaep(data []byte, hash, mgfHash crypto.Hash, label []byte) ([]byte, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+	return publicEncryptOaep(&p.priKey.PublicKey, data, hash, mgfHash, label)
+}
+
+// Private decrypt of OAEP.
+// Each bits/8 bytes will be decrypted to bits/8-2*hLen-2 or less bytes.
+// If len(data) == 0, will return an empty buf too.
+//
+// The hash, mgfHash and label must be the same as the ones used to encrypt.
+//
+// The result will not share the array of data.
+func (p RsaPrivate) PrivateDecryptOaep(data []byte, hash, mgfHash crypto.Hash, label []byte) ([]byte, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+	return privateDecryptOaep(p.priKey, data, hash, mgfHash, label)
 }
 
 // If p has error, return nil.
@@ -80,22 +308,77 @@ func (p RsaPrivate) GetNBytes() []byte {
 	return p.priKey.PublicKey.N.Bytes()
 }
 
+// It may has an error, call HasError to see it.
 type RsaPublic struct {
 	pubKey *rsa.PublicKey
+	err    error
 }
 
 // n:
 // The bytes of N. The size of N is the length of the public modulus.
+// The bit size of N must not be less than RsaDefaultMinPublicKeyBits.
 //
 // e:
 // The public exponent E value. Usually use 65537.
+// It must be odd, greater than 1 and not greater than RsaMaxPublicExponent.
+//
+// Can call HasError to see if it has an error.
 func NewRsaPublic(n []byte, e int) RsaPublic {
-	return RsaPublic{
-		pubKey: &rsa.PublicKey{
-			N: new(big.Int).SetBytes(n),
-			E: e,
-		},
+	return NewRsaPublicWithMinBits(n, e, RsaDefaultMinPublicKeyBits)
+}
+
+// The same as NewRsaPublic, except that the minimum bit size of N is minBits,
+// such as 2048 to reject the weak keys in production.
+//
+// Can call HasError to see if it has an error.
+func NewRsaPublicWithMinBits(n []byte, e int, minBits int) RsaPublic {
+	return newRsaPublic(&rsa.PublicKey{
+		N: new(big.Int).SetBytes(n),
+		E: e,
+	}, minBits)
+}
+
+// Validate the key and create RsaPublic. The key is used directly but not copied.
+func newRsaPublic(pubKey *rsa.PublicKey, minBits int) RsaPublic {
+	if pubKey.N.Sign() <= 0 || pubKey.N.Bit(0) == 0 {
+		return RsaPublic{err: errRsaModulusIllegal}
+	}
+	if pubKey.N.BitLen() < minBits {
+		return RsaPublic{err: errRsaPublicKeyTooSmall}
+	}
+	if err := checkRsaPublicExponent(pubKey.E); err != nil {
+		return RsaPublic{err: err}
+	}
+	return RsaPublic{pubKey: pubKey}
+}
+
+func checkRsaPublicExponent(e int) error {
+	if e <= 1 || e%2 == 0 || e > RsaMaxPublicExponent {
+		return errRsaPublicExponent
 	}
+	return nil
+}
+
+func (pub RsaPublic) HasError() (error, bool) {
+	return pub.err, pub.err != nil
+}
+
+// The bit size of N.
+// If pub has error, return 0.
+func (pub *RsaPublic) Bits() int {
+	if pub.err != nil {
+		return 0
+	}
+	return pub.pubKey.N.BitLen()
+}
+
+// The byte size of N, which is also the size of each encrypted block and signature.
+// If pub has error, return 0.
+func (pub *RsaPublic) Size() int {
+	if pub.err != nil {
+		return 0
+	}
+	return rsaModulusSize(pub.pubKey)
 }
 
 // Public encrypt of PKCS#1 v1.5.
@@ -104,61 +387,236 @@ func NewRsaPublic(n []byte, e int) RsaPublic {
 //
 // The result will not share the array of data.
 func (pub *RsaPublic) PublicEncryptPkcs1v15(data []byte) ([]byte, error) {
-	return publicEncryptPkcs1v15(pub.pubKey, data)
+	if pub.err != nil {
+		return nil, pub.err
+	}
+	return publicEncryptPkcs1v15(pub.pubKey, data, 1)
 }
 
-func publicEncryptPkcs1v15(pub *rsa.PublicKey, data []byte) ([]byte, error) {
+// The same as PublicEncryptPkcs1v15, except that the blocks are encrypted by workers goroutines in parallel.
+// If workers is 0, runtime.GOMAXPROCS(0) is used.
+//
+// The result will not share the array of data.
+func (pub *RsaPublic) PublicEncryptPkcs1v15Parallel(data []byte, workers int) ([]byte, error) {
+	if pub.err != nil {
+		return nil, pub.err
+	}
+	return publicEncryptPkcs1v15(pub.pubKey, data, workers)
+}
+
+// Public encrypt of OAEP.
+// Each bits/8-2*hLen-2 or less bytes will be encrypted to bits/8 bytes, hLen is the size of hash.
+// If len(data) == 0, will return an empty buf too.
+//
+// hash:
+// The hash of OAEP. It must be crypto.SHA1, crypto.SHA256, crypto.SHA384 or crypto.SHA512.
+//
+// mgfHash:
+// The hash of MGF1. If it is 0, hash is used. It must be one of the hashes which hash can be.
+// For example, Java "RSA/ECB/OAEPWithSHA-256AndMGF1Padding" uses crypto.SHA256 and crypto.SHA1 by default.
+//
+// label:
+// It is not encrypted, but must be the same when decrypting. It can be nil.
+//
+// The result will not share the array of data.
+func (pub *RsaPublic) PublicEncryptOaep(data []byte, hash, mgfHash crypto.Hash, label []byte) ([]byte, error) {
+	if pub.err != nil {
+		return nil, pub.err
+	}
+	return publicEncryptOaep(pub.pubKey, data, hash, mgfHash, label)
+}
+
+func publicEncryptPkcs1v15(pub *rsa.PublicKey, data []byte, workers int) ([]byte, error) {
 	// Each input must be not longer than the length of bytes of the public modulus minus 11 bytes.
 	// The output is always equal to the length of bytes of the public modulus.
-	eachSize := pub.N.BitLen()/8 - 11
-	if eachSize <= 0 {
-		return nil, errRsaPublicKeySize
+	eachSize := rsaModulusSize(pub) - 11
+	return cryptChunks(data, eachSize, workers, func(chunk []byte) ([]byte, error) {
+		return rsa.EncryptPKCS1v15(rand.Reader, pub, chunk)
+	})
+}
+
+func privateDecryptPkcs1v15(pri *rsa.PrivateKey, data []byte, workers int) ([]byte, error) {
+	// See publicEncryptPkcs1v15. The logical procedure is reversed.
+	eachSize := rsaModulusSize(&pri.PublicKey)
+	return cryptChunks(data, eachSize, workers, func(chunk []byte) ([]byte, error) {
+		return rsa.DecryptPKCS1v15(rand.Reader, pri, chunk)
+	})
+}
+
+// Public decrypt of PKCS#1 v1.5, with the type 1 padding. It is the reverse of RsaPrivate.PrivateEncryptPkcs1v15.
+// Each bits/8 bytes will be decrypted to bits/8-11 or less bytes.
+// If len(data) == 0, will return an empty buf too.
+//
+// The result will not share the array of data.
+func (pub *RsaPublic) PublicDecryptPkcs1v15(data []byte) ([]byte, error) {
+	if pub.err != nil {
+		return nil, pub.err
 	}
-	var outputs [][]byte
-	for encSize, leftSize, thisSize := 0, len(data), 0; leftSize > 0; {
-		if leftSize > eachSize {
-			thisSize = eachSize
-		} else {
-			thisSize = leftSize
+	return publicDecryptPkcs1v15(pub.pubKey, data)
+}
+
+func privateEncryptPkcs1v15(pri *rsa.PrivateKey, data []byte) ([]byte, error) {
+	// See publicEncryptPkcs1v15. Signing with hash 0 pads the input with the type 1 padding directly.
+	eachSize := rsaModulusSize(&pri.PublicKey) - 11
+	return cryptChunks(data, eachSize, 1, func(chunk []byte) ([]byte, error) {
+		return rsa.SignPKCS1v15(nil, pri, 0, chunk)
+	})
+}
+
+func publicDecryptPkcs1v15(pub *rsa.PublicKey, data []byte) ([]byte, error) {
+	// See privateEncryptPkcs1v15. The logical procedure is reversed.
+	eachSize := rsaModulusSize(pub)
+	e := big.NewInt(int64(pub.E))
+	return cryptChunks(data, eachSize, 1, func(chunk []byte) ([]byte, error) {
+		if len(chunk) != eachSize {
+			return nil, errRsaCiphertextIllegal
 		}
-		out, err := rsa.EncryptPKCS1v15(rand.Reader, pub, data[encSize:(encSize+thisSize)])
-		if err != nil {
-			return nil, err
+		c := new(big.Int).SetBytes(chunk)
+		if c.Cmp(pub.N) >= 0 {
+			return nil, errRsaCiphertextIllegal
 		}
-		outputs = append(outputs, out)
-		encSize += thisSize
-		leftSize -= thisSize
+		em := make([]byte, eachSize)
+		new(big.Int).Exp(c, e, pub.N).FillBytes(em)
+		// em = 0x00 || 0x01 || PS || 0x00 || M, PS is at least 8 bytes of 0xff.
+		if em[0] != 0 || em[1] != 1 {
+			return nil, errRsaPkcs1v15Type1Padding
+		}
+		i := 2
+		for i < len(em) && em[i] == 0xff {
+			i++
+		}
+		if i < 10 || i == len(em) || em[i] != 0 {
+			return nil, errRsaPkcs1v15Type1Padding
+		}
+		return em[i+1:], nil
+	})
+}
+
+func publicEncryptOaep(pub *rsa.PublicKey, data []byte, hash, mgfHash crypto.Hash, label []byte) ([]byte, error) {
+	opts, err := newRsaOaepOptions(hash, mgfHash, label)
+	if err != nil {
+		return nil, err
 	}
-	return bytes.Join(outputs, nil), nil
+	// Each input must be not longer than the length of bytes of the public modulus minus twice the hash size plus 2.
+	// The output is always equal to the length of bytes of the public modulus.
+	eachSize := rsaModulusSize(pub) - 2*hash.Size() - 2
+	return cryptChunks(data, eachSize, 1, func(chunk []byte) ([]byte, error) {
+		return rsa.EncryptOAEPWithOptions(rand.Reader, pub, chunk, opts)
+	})
 }
 
-func privateDecryptPkcs1v15(pri *rsa.PrivateKey, data []byte) ([]byte, error) {
-	// See publicEncryptPkcs1v15. The logical procedure is reversed.
-	eachSize := pri.PublicKey.N.BitLen() / 8
+func privateDecryptOaep(pri *rsa.PrivateKey, data []byte, hash, mgfHash crypto.Hash, label []byte) ([]byte, error) {
+	opts, err := newRsaOaepOptions(hash, mgfHash, label)
+	if err != nil {
+		return nil, err
+	}
+	// See publicEncryptOaep. The logical procedure is reversed.
+	eachSize := rsaModulusSize(&pri.PublicKey)
+	return cryptChunks(data, eachSize, 1, func(chunk []byte) ([]byte, error) {
+		return pri.Decrypt(nil, chunk, opts)
+	})
+}
+
+func newRsaOaepOptions(hash, mgfHash crypto.Hash, label []byte) (*rsa.OAEPOptions, error) {
+	if mgfHash == 0 {
+		mgfHash = hash
+	}
+	if !isRsaOaepHash(hash) || !isRsaOaepHash(mgfHash) {
+		return nil, errRsaOaepHashUnsupported
+	}
+	return &rsa.OAEPOptions{
+		Hash:    hash,
+		MGFHash: mgfHash,
+		Label:   label,
+	}, nil
+}
+
+func isRsaOaepHash(hash crypto.Hash) bool {
+	switch hash {
+	case crypto.SHA1, crypto.SHA256, crypto.SHA384, crypto.SHA512:
+		return hash.Available()
+	}
+	return false
+}
+
+// The length of bytes of the public modulus.
+func rsaModulusSize(pub *rsa.PublicKey) int {
+	return (pub.N.BitLen() + 7) / 8
+}
+
+// Split data into eachSize or less bytes, crypt each of them, and join the results in order.
+// If workers is not 1, the chunks are crypted by workers goroutines in parallel,
+// and 0 means runtime.GOMAXPROCS(0).
+func cryptChunks(data []byte, eachSize, workers int, crypt func([]byte) ([]byte, error)) ([]byte, error) {
 	if eachSize <= 0 {
 		return nil, errRsaPublicKeySize
 	}
-	var outputs [][]byte
+	var chunks [][]byte
 	for encSize, leftSize, thisSize := 0, len(data), 0; leftSize > 0; {
 		if leftSize > eachSize {
 			thisSize = eachSize
 		} else {
 			thisSize = leftSize
 		}
-		out, err := rsa.DecryptPKCS1v15(rand.Reader, pri, data[encSize:(encSize+thisSize)])
+		chunks = append(chunks, data[encSize:(encSize+thisSize)])
+		encSize += thisSize
+		leftSize -= thisSize
+	}
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if workers > len(chunks) {
+		workers = len(chunks)
+	}
+
+	outputs := make([][]byte, len(chunks))
+	if workers <= 1 {
+		for i, chunk := range chunks {
+			out, err := crypt(chunk)
+			if err != nil {
+				return nil, err
+			}
+			outputs[i] = out
+		}
+		return bytes.Join(outputs, nil), nil
+	}
+
+	errs := make([]error, len(chunks))
+	var failed int32
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				outputs[i], errs[i] = crypt(chunks[i])
+				if errs[i] != nil {
+					atomic.StoreInt32(&failed, 1)
+				}
+			}
+		}()
+	}
+	for i := range chunks {
+		if atomic.LoadInt32(&failed) != 0 {
+			break
+		}
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+	for _, err := range errs {
 		if err != nil {
 			return nil, err
 		}
-		outputs = append(outputs, out)
-		encSize += thisSize
-		leftSize -= thisSize
 	}
 	return bytes.Join(outputs, nil), nil
 }
 
 // This is copy from crypto/rsa.GenerateMultiPrimeKey, except pass an additional e param and can set priv.E as it.
 // In the rsa.GenerateMultiPrimeKey, priv.E is always 65537.
-func generateMultiPrimeKey(random io.Reader, nprimes int, bits int, e int) (priv *rsa.PrivateKey, err error) {
+// It also stops when ctx is done, and the primes are generated by randomPrime to use the random param.
+func generateMultiPrimeKey(ctx context.Context, random io.Reader, nprimes int, bits int, e int) (priv *rsa.PrivateKey, err error) {
 	priv = new(rsa.PrivateKey)
 	priv.E = e // In crypto/rsa.GenerateMultiPrimeKey, it is always 65537.
 
@@ -186,7 +644,7 @@ NextSetOfPrimes:
 			todo += (nprimes - 2) / 5
 		}
 		for i := 0; i < nprimes; i++ {
-			primes[i], err = rand.Prime(random, todo/(nprimes-i))
+			primes[i], err = randomPrime(ctx, random, todo/(nprimes-i))
 			if err != nil {
 				return nil, err
 			}
@@ -237,3 +695,46 @@ NextSetOfPrimes:
 	priv.Precompute()
 	return
 }
+
+// This is copy from crypto/rand.Prime of Go 1.15, except that it stops when ctx is done.
+// Since Go 1.26, crypto/rand.Prime ignores the random param, so it can not be used for deterministic tests.
+func randomPrime(ctx context.Context, random io.Reader, bits int) (p *big.Int, err error) {
+	if bits < 2 {
+		return nil, errors.New("crypto/rand: prime size must be at least 2-bit")
+	}
+	b := uint(bits % 8)
+	if b == 0 {
+		b = 8
+	}
+	bytes := make([]byte, (bits+7)/8)
+	p = new(big.Int)
+	for {
+		if err = ctx.Err(); err != nil {
+			return nil, err
+		}
+		if _, err = io.ReadFull(random, bytes); err != nil {
+			return nil, err
+		}
+		// Clear bits in the first byte to make sure the candidate has a size <= bits.
+		bytes[0] &= uint8(int(1<<b) - 1)
+		// Don't let the value be too small, i.e, set the most significant two bits.
+		// Setting the top two bits, rather than just the top bit,
+		// means that when two of these values are multiplied together,
+		// the result isn't ever one bit short.
+		if b >= 2 {
+			bytes[0] |= 3 << (b - 2)
+		} else {
+			// Here b==1, because b cannot be zero.
+			bytes[0] |= 1
+			if len(bytes) > 1 {
+				bytes[1] |= 0x80
+			}
+		}
+		// Make the value odd since an even number this large certainly isn't prime.
+		bytes[len(bytes)-1] |= 1
+		p.SetBytes(bytes)
+		if p.ProbablyPrime(20) {
+			return p, nil
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package crypt
+
+import (
+	"crypto"
+	"io"
+)
+
+var (
+	_ crypto.Signer    = RsaCryptoSigner{}
+	_ crypto.Decrypter = RsaCryptoSigner{}
+)
+
+// It implements crypto.Signer and crypto.Decrypter by the private key,
+// so that the key can be used by crypto/tls, crypto/x509 and the other libraries.
+// The private key is not exposed by it.
+type RsaCryptoSigner struct {
+	p RsaPrivate
+}
+
+// Return the crypto.Signer and crypto.Decrypter of the private key.
+func (p RsaPrivate) CryptoSigner() RsaCryptoSigner {
+	return RsaCryptoSigner{p: p}
+}
+
+// Return the *rsa.PublicKey. If the private key has error, return nil.
+func (s RsaCryptoSigner) Public() crypto.PublicKey {
+	if s.p.err != nil {
+		return nil
+	}
+	pubKey := s.p.priKey.PublicKey
+	return &pubKey
+}
+
+// Sign the digest. If opts is *rsa.PSSOptions, sign of PSS, otherwise sign of PKCS#1 v1.5 with opts.HashFunc().
+// The rand param is passed to crypto/rsa.
+func (s RsaCryptoSigner) Sign(rand io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	if s.p.err != nil {
+		return nil, s.p.err
+	}
+	return s.p.priKey.Sign(rand, digest, opts)
+}
+
+// Decrypt msg. If opts is *rsa.OAEPOptions, decrypt of OAEP.
+// If opts is nil or *rsa.PKCS1v15DecryptOptions, decrypt of PKCS#1 v1.5.
+// Unlike PrivateDecryptPkcs1v15 and PrivateDecryptOaep, msg must be a single block.
+func (s RsaCryptoSigner) Decrypt(rand io.Reader, msg []byte, opts crypto.DecrypterOpts) ([]byte, error) {
+	if s.p.err != nil {
+		return nil, s.p.err
+	}
+	return s.p.priKey.Decrypt(rand, msg, opts)
+}
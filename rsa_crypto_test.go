@@ -0,0 +1,94 @@
+package crypt
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func TestRsaCryptoSignerSign(t *testing.T) {
+	pri := NewRsaPrivate(1024, 65537)
+	pub := pri.Public()
+	signer := pri.CryptoSigner()
+	if pubKey, ok := signer.Public().(*rsa.PublicKey); !ok || pubKey.N.Cmp(pub.pubKey.N) != 0 {
+		t.Fatal("the public key is wrong")
+	}
+	digest := sha256.Sum256([]byte("I love this girl! Does she?"))
+
+	sig, err := signer.Sign(rand.Reader, digest[:], crypto.SHA256)
+	if err != nil {
+		t.Fatal("sign err:", err)
+	}
+	if err := pub.VerifyPkcs1v15Digest(digest[:], sig, crypto.SHA256); err != nil {
+		t.Error("verify pkcs1v15 err:", err)
+	}
+	sig, err = signer.Sign(rand.Reader, digest[:], &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash, Hash: crypto.SHA256})
+	if err != nil {
+		t.Fatal("sign pss err:", err)
+	}
+	if err := pub.VerifyPssDigest(digest[:], sig, crypto.SHA256, RsaPssSaltLengthEqualsHash); err != nil {
+		t.Error("verify pss err:", err)
+	}
+
+	// It can be used by crypto/x509.
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "test"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, signer.Public(), signer)
+	if err != nil {
+		t.Fatal("create certificate err:", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal("parse certificate err:", err)
+	}
+	if err := cert.CheckSignature(cert.SignatureAlgorithm, cert.RawTBSCertificate, cert.Signature); err != nil {
+		t.Error("check certificate signature err:", err)
+	}
+
+	signer = RsaPrivate{err: errRsaPublicKeySize}.CryptoSigner()
+	if signer.Public() != nil {
+		t.Error("the public key of the private key with error should be nil")
+	}
+	if _, err := signer.Sign(rand.Reader, digest[:], crypto.SHA256); err != errRsaPublicKeySize {
+		t.Error("sign by the private key with error err:", err)
+	}
+}
+
+func TestRsaCryptoSignerDecrypt(t *testing.T) {
+	pri := NewRsaPrivate(1024, 65537)
+	signer := pri.CryptoSigner()
+	input := []byte("I love this girl! Does she?")
+
+	enc, _ := pri.PublicEncryptPkcs1v15(input)
+	for _, opts := range []crypto.DecrypterOpts{nil, &rsa.PKCS1v15DecryptOptions{}} {
+		dec, err := signer.Decrypt(rand.Reader, enc, opts)
+		if err != nil || string(dec) != string(input) {
+			t.Error("decrypt pkcs1v15 err:", opts, err)
+		}
+	}
+	// The session key is random if the decryption fails.
+	enc[0] ^= 1
+	dec, err := signer.Decrypt(rand.Reader, enc, &rsa.PKCS1v15DecryptOptions{SessionKeyLen: 16})
+	if err != nil || len(dec) != 16 {
+		t.Error("decrypt session key err:", err)
+	}
+
+	enc, _ = pri.PublicEncryptOaep(input, crypto.SHA256, 0, []byte("label"))
+	dec, err = signer.Decrypt(rand.Reader, enc, &rsa.OAEPOptions{Hash: crypto.SHA256, Label: []byte("label")})
+	if err != nil || string(dec) != string(input) {
+		t.Error("decrypt oaep err:", err)
+	}
+	if _, err := signer.Decrypt(rand.Reader, enc, &rsa.OAEPOptions{Hash: crypto.SHA256}); err == nil {
+		t.Error("decrypt oaep with other label should have error")
+	}
+}
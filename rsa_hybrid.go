@@ -0,0 +1,107 @@
+package crypt
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"io"
+)
+
+const (
+	// RSA-OAEP with SHA-256 and MGF1-SHA256 wraps an AES-256 key, which seals the payload by AES-256-GCM.
+	RsaHybridOaepSha256AesGcm = 1
+
+	rsaHybridVersion1   = 1
+	rsaHybridHeaderSize = 2 // The version byte and the algorithm byte.
+)
+
+var (
+	errRsaHybridVersionUnknown   = errors.New("rsa hybrid version unknown")
+	errRsaHybridAlgorithmUnknown = errors.New("rsa hybrid algorithm unknown")
+	errRsaHybridTooShort         = errors.New("rsa hybrid data too short")
+)
+
+// Seal plaintext of any size with a random AES key, and wrap the key with the public key.
+// It is much faster and smaller than PublicEncryptOaep for large data.
+//
+// The layout of the result is:
+//
+//	version (1 byte) | algorithm (1 byte) | wrapped key (bits/8 bytes) | ciphertext | tag (16 bytes)
+//
+// The version is 1 and the algorithm is RsaHybridOaepSha256AesGcm.
+// The wrapped key is the RSA-OAEP encryption of a random 32 bytes AES key, whose label is the first 2 bytes.
+// The ciphertext and tag are the AES-256-GCM sealing of plaintext with an all zero nonce,
+// which is safe because the AES key is used only once. The additional data of AES-GCM is
+// everything before the ciphertext followed by the additionalData param.
+//
+// additionalData:
+// It is authenticated but not encrypted, and must be the same when opening. It can be nil.
+func (pub *RsaPublic) SealHybrid(plaintext, additionalData []byte) ([]byte, error) {
+	if pub.err != nil {
+		return nil, pub.err
+	}
+	header := []byte{rsaHybridVersion1, RsaHybridOaepSha256AesGcm}
+	key := make([]byte, Aes256KeySize)
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		return nil, err
+	}
+	wrapped, err := rsa.EncryptOAEPWithOptions(rand.Reader, pub.pubKey, key, &rsa.OAEPOptions{
+		Hash:  crypto.SHA256,
+		Label: header,
+	})
+	if err != nil {
+		return nil, err
+	}
+	prefix := append(header, wrapped...)
+	enc, err := NewAesGcm(key, 0, 0).Seal(make([]byte, AesGcmStandardNonceSize), plaintext, rsaHybridAdditionalData(prefix, additionalData))
+	if err != nil {
+		return nil, err
+	}
+	return append(prefix, enc...), nil
+}
+
+// The same as RsaPublic.SealHybrid.
+func (p RsaPrivate) SealHybrid(plaintext, additionalData []byte) ([]byte, error) {
+	pub := p.Public()
+	return pub.SealHybrid(plaintext, additionalData)
+}
+
+// Open the result of SealHybrid.
+// If the data or additionalData has been tampered, or the private key is wrong, return ErrAuthenticationFailed.
+//
+// The result will not share the array of sealed.
+func (p RsaPrivate) OpenHybrid(sealed, additionalData []byte) ([]byte, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+	if len(sealed) < rsaHybridHeaderSize {
+		return nil, errRsaHybridTooShort
+	}
+	if sealed[0] != rsaHybridVersion1 {
+		return nil, errRsaHybridVersionUnknown
+	}
+	if sealed[1] != RsaHybridOaepSha256AesGcm {
+		return nil, errRsaHybridAlgorithmUnknown
+	}
+	wrappedEnd := rsaHybridHeaderSize + p.Size()
+	if len(sealed) < wrappedEnd+AesGcmStandardTagSize {
+		return nil, errRsaHybridTooShort
+	}
+	key, err := p.priKey.Decrypt(nil, sealed[rsaHybridHeaderSize:wrappedEnd], &rsa.OAEPOptions{
+		Hash:  crypto.SHA256,
+		Label: sealed[:rsaHybridHeaderSize],
+	})
+	if err != nil || len(key) != Aes256KeySize {
+		// Do not tell apart the failure of key unwrapping and of AES-GCM.
+		return nil, ErrAuthenticationFailed
+	}
+	prefix := sealed[:wrappedEnd]
+	return NewAesGcm(key, 0, 0).Open(make([]byte, AesGcmStandardNonceSize), sealed[wrappedEnd:], rsaHybridAdditionalData(prefix, additionalData))
+}
+
+func rsaHybridAdditionalData(prefix, additionalData []byte) []byte {
+	result := make([]byte, 0, len(prefix)+len(additionalData))
+	result = append(result, prefix...)
+	return append(result, additionalData...)
+}
@@ -0,0 +1,57 @@
+package crypt
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestRsaHybridSealAndOpen(t *testing.T) {
+	bits, e := 1024, 65537
+	pri := NewRsaPrivate(bits, e)
+	pub := pri.Public()
+	ad := []byte("header")
+
+	for _, size := range []int{0, 1, 100, 100000} {
+		plaintext := bytes.Repeat([]byte{'a'}, size)
+		sealed, err := pub.SealHybrid(plaintext, ad)
+		if err != nil {
+			t.Fatal("seal err:", size, err)
+		}
+		if len(sealed) != rsaHybridHeaderSize+bits/8+size+AesGcmStandardTagSize {
+			t.Error("the sealed size is wrong:", size, len(sealed))
+		}
+		opened, err := pri.OpenHybrid(sealed, ad)
+		if err != nil {
+			t.Fatal("open err:", size, err)
+		}
+		if !bytes.Equal(opened, plaintext) {
+			t.Error("the opened data is wrong:", size)
+		}
+	}
+
+	sealed, err := pri.SealHybrid([]byte("I love this girl! Does she?"), nil)
+	if err != nil {
+		t.Fatal("seal err:", err)
+	}
+	if _, err := pri.OpenHybrid(sealed, ad); err != ErrAuthenticationFailed {
+		t.Error("open with other additional data err:", err)
+	}
+	for _, i := range []int{rsaHybridHeaderSize, rsaHybridHeaderSize + bits/8, len(sealed) - 1} {
+		tampered := append([]byte(nil), sealed...)
+		tampered[i] ^= 1
+		if _, err := pri.OpenHybrid(tampered, nil); err != ErrAuthenticationFailed {
+			t.Error("open tampered data err:", i, err)
+		}
+	}
+	if _, err := NewRsaPrivate(bits, e).OpenHybrid(sealed, nil); err != ErrAuthenticationFailed {
+		t.Error("open with other key err:", err)
+	}
+	tampered := append([]byte(nil), sealed...)
+	tampered[0] = 2
+	if _, err := pri.OpenHybrid(tampered, nil); err != errRsaHybridVersionUnknown {
+		t.Error("open unknown version err:", err)
+	}
+	if _, err := pri.OpenHybrid(sealed[:rsaHybridHeaderSize+bits/8], nil); err != errRsaHybridTooShort {
+		t.Error("open short data err:", err)
+	}
+}
@@ -0,0 +1,225 @@
+package crypt
+
+import (
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+)
+
+const (
+	PemTypeRsaPrivateKey = "RSA PRIVATE KEY" // PKCS#1 private key.
+	PemTypePrivateKey    = "PRIVATE KEY"     // PKCS#8 private key.
+	PemTypePublicKey     = "PUBLIC KEY"      // PKIX public key.
+	PemTypeRsaPublicKey  = "RSA PUBLIC KEY"  // PKCS#1 public key.
+)
+
+var (
+	errRsaPemNoKey     = errors.New("no rsa key found in pem")
+	errRsaDerIllegal   = errors.New("der is not a rsa key")
+	errRsaKeyIsNotRsa  = errors.New("key is not rsa")
+	errRsaPemEncrypted = errors.New("pem is encrypted, parse it by ParseEncryptedRsaPrivatePem")
+)
+
+// Marshal the private key to PKCS#1 DER.
+func (p RsaPrivate) MarshalPkcs1PrivateKeyDer() ([]byte, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+	return x509.MarshalPKCS1PrivateKey(p.priKey), nil
+}
+
+// Marshal the private key to PKCS#1 PEM, whose type is "RSA PRIVATE KEY".
+func (p RsaPrivate) MarshalPkcs1PrivateKeyPem() ([]byte, error) {
+	der, err := p.MarshalPkcs1PrivateKeyDer()
+	if err != nil {
+		return nil, err
+	}
+	return encodePem(PemTypeRsaPrivateKey, der), nil
+}
+
+// Marshal the private key to PKCS#8 DER.
+func (p RsaPrivate) MarshalPkcs8PrivateKeyDer() ([]byte, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+	return x509.MarshalPKCS8PrivateKey(p.priKey)
+}
+
+// Marshal the private key to PKCS#8 PEM, whose type is "PRIVATE KEY".
+func (p RsaPrivate) MarshalPkcs8PrivateKeyPem() ([]byte, error) {
+	der, err := p.MarshalPkcs8PrivateKeyDer()
+	if err != nil {
+		return nil, err
+	}
+	return encodePem(PemTypePrivateKey, der), nil
+}
+
+// Marshal the public key to PKIX DER, which is also known as SubjectPublicKeyInfo.
+func (pub *RsaPublic) MarshalPkixPublicKeyDer() ([]byte, error) {
+	if pub.err != nil {
+		return nil, pub.err
+	}
+	return x509.MarshalPKIXPublicKey(pub.pubKey)
+}
+
+// Marshal the public key to PKIX PEM, whose type is "PUBLIC KEY".
+func (pub *RsaPublic) MarshalPkixPublicKeyPem() ([]byte, error) {
+	der, err := pub.MarshalPkixPublicKeyDer()
+	if err != nil {
+		return nil, err
+	}
+	return encodePem(PemTypePublicKey, der), nil
+}
+
+// Marshal the public key to PKCS#1 DER.
+func (pub *RsaPublic) MarshalPkcs1PublicKeyDer() ([]byte, error) {
+	if pub.err != nil {
+		return nil, pub.err
+	}
+	return x509.MarshalPKCS1PublicKey(pub.pubKey), nil
+}
+
+// Marshal the public key to PKCS#1 PEM, whose type is "RSA PUBLIC KEY".
+func (pub *RsaPublic) MarshalPkcs1PublicKeyPem() ([]byte, error) {
+	der, err := pub.MarshalPkcs1PublicKeyDer()
+	if err != nil {
+		return nil, err
+	}
+	return encodePem(PemTypeRsaPublicKey, der), nil
+}
+
+// Parse the first "RSA PRIVATE KEY" or "PRIVATE KEY" block in data.
+// The other blocks are skipped.
+// If the block is encrypted by the legacy OpenSSL "Proc-Type" header, use ParseEncryptedRsaPrivatePem instead.
+func ParseRsaPrivatePem(data []byte) (RsaPrivate, error) {
+	block := decodePem(data, PemTypeRsaPrivateKey, PemTypePrivateKey)
+	if block == nil {
+		return RsaPrivate{err: errRsaPemNoKey}, errRsaPemNoKey
+	}
+	if _, ok := block.Headers["Proc-Type"]; ok {
+		return RsaPrivate{err: errRsaPemEncrypted}, errRsaPemEncrypted
+	}
+	if block.Type == PemTypeRsaPrivateKey {
+		return parseRsaPrivatePkcs1Der(block.Bytes)
+	}
+	return parseRsaPrivatePkcs8Der(block.Bytes)
+}
+
+// Parse PKCS#1 or PKCS#8 DER of a private key.
+func ParseRsaPrivateDer(der []byte) (RsaPrivate, error) {
+	if pri, err := parseRsaPrivatePkcs1Der(der); err == nil {
+		return pri, nil
+	}
+	if pri, err := parseRsaPrivatePkcs8Der(der); err == nil || err == errRsaKeyIsNotRsa {
+		return pri, err
+	}
+	return RsaPrivate{err: errRsaDerIllegal}, errRsaDerIllegal
+}
+
+func parseRsaPrivatePkcs1Der(der []byte) (RsaPrivate, error) {
+	key, err := x509.ParsePKCS1PrivateKey(der)
+	if err != nil {
+		return RsaPrivate{err: err}, err
+	}
+	return RsaPrivate{priKey: key}, nil
+}
+
+func parseRsaPrivatePkcs8Der(der []byte) (RsaPrivate, error) {
+	key, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		return RsaPrivate{err: err}, err
+	}
+	priKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return RsaPrivate{err: errRsaKeyIsNotRsa}, errRsaKeyIsNotRsa
+	}
+	return RsaPrivate{priKey: priKey}, nil
+}
+
+// Parse the first "PUBLIC KEY" or "RSA PUBLIC KEY" block in data.
+// The other blocks are skipped.
+// The bit size of N must not be less than RsaDefaultMinPublicKeyBits.
+func ParseRsaPublicPem(data []byte) (RsaPublic, error) {
+	return ParseRsaPublicPemWithMinBits(data, RsaDefaultMinPublicKeyBits)
+}
+
+// The same as ParseRsaPublicPem, except that the minimum bit size of N is minBits,
+// such as 2048 to reject the weak keys in production.
+func ParseRsaPublicPemWithMinBits(data []byte, minBits int) (RsaPublic, error) {
+	block := decodePem(data, PemTypePublicKey, PemTypeRsaPublicKey)
+	if block == nil {
+		return RsaPublic{err: errRsaPemNoKey}, errRsaPemNoKey
+	}
+	if block.Type == PemTypeRsaPublicKey {
+		return parseRsaPublicPkcs1Der(block.Bytes, minBits)
+	}
+	return parseRsaPublicPkixDer(block.Bytes, minBits)
+}
+
+// Parse PKIX or PKCS#1 DER of a public key.
+// The bit size of N must not be less than RsaDefaultMinPublicKeyBits.
+func ParseRsaPublicDer(der []byte) (RsaPublic, error) {
+	return ParseRsaPublicDerWithMinBits(der, RsaDefaultMinPublicKeyBits)
+}
+
+// The same as ParseRsaPublicDer, except that the minimum bit size of N is minBits,
+// such as 2048 to reject the weak keys in production.
+func ParseRsaPublicDerWithMinBits(der []byte, minBits int) (RsaPublic, error) {
+	if pub, err := parseRsaPublicPkixDer(der, minBits); err == nil || err == errRsaKeyIsNotRsa || err == errRsaPublicKeyTooSmall {
+		return pub, err
+	}
+	if pub, err := parseRsaPublicPkcs1Der(der, minBits); err == nil || err == errRsaPublicKeyTooSmall {
+		return pub, err
+	}
+	return RsaPublic{err: errRsaDerIllegal}, errRsaDerIllegal
+}
+
+func parseRsaPublicPkixDer(der []byte, minBits int) (RsaPublic, error) {
+	key, err := x509.ParsePKIXPublicKey(der)
+	if err != nil {
+		return RsaPublic{err: err}, err
+	}
+	pubKey, ok := key.(*rsa.PublicKey)
+	if !ok {
+		return RsaPublic{err: errRsaKeyIsNotRsa}, errRsaKeyIsNotRsa
+	}
+	return newParsedRsaPublic(pubKey, minBits)
+}
+
+func parseRsaPublicPkcs1Der(der []byte, minBits int) (RsaPublic, error) {
+	pubKey, err := x509.ParsePKCS1PublicKey(der)
+	if err != nil {
+		return RsaPublic{err: err}, err
+	}
+	return newParsedRsaPublic(pubKey, minBits)
+}
+
+// Validate the parsed key like NewRsaPublicWithMinBits.
+func newParsedRsaPublic(pubKey *rsa.PublicKey, minBits int) (RsaPublic, error) {
+	pub := newRsaPublic(pubKey, minBits)
+	return pub, pub.err
+}
+
+func encodePem(typ string, der []byte) []byte {
+	return pem.EncodeToMemory(&pem.Block{
+		Type:  typ,
+		Bytes: der,
+	})
+}
+
+// Return the first block whose type is one of types, or nil if not found.
+func decodePem(data []byte, types ...string) *pem.Block {
+	for {
+		block, rest := pem.Decode(data)
+		if block == nil {
+			return nil
+		}
+		for _, typ := range types {
+			if block.Type == typ {
+				return block
+			}
+		}
+		data = rest
+	}
+}
@@ -0,0 +1,379 @@
+package crypt
+
+import (
+	"crypto"
+	"crypto/cipher"
+	"crypto/des"
+	"crypto/md5"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"hash"
+	"io"
+	"strings"
+)
+
+const (
+	PemTypeEncryptedPrivateKey = "ENCRYPTED PRIVATE KEY" // PKCS#8 encrypted private key.
+
+	// The default iteration count of PBKDF2.
+	Pkcs8DefaultIterations = 100000
+	// The maximum iteration count of PBKDF2. A larger count from an untrusted key would hang the parsing.
+	Pkcs8MaxIterations = 10 * Pkcs8DefaultIterations
+	// The byte size of PBKDF2 salt.
+	pkcs8SaltSize = 16
+)
+
+var (
+	oidPbes2          = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 13}
+	oidPbkdf2         = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 12}
+	oidHmacWithSha1   = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 7}
+	oidHmacWithSha256 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 9}
+	oidAes128Cbc      = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 2}
+	oidAes192Cbc      = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 22}
+	oidAes256Cbc      = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 42}
+)
+
+var (
+	errPkcs8EncryptionUnsupported  = errors.New("pkcs8 encryption algorithm unsupported")
+	errPkcs8PrfUnsupported         = errors.New("pkcs8 pbkdf2 prf unsupported")
+	errPkcs8IterationsIllegal      = errors.New("pkcs8 pbkdf2 iterations illegal")
+	errPkcs8PasswordIncorrect      = errors.New("private key password incorrect")
+	errEncryptedKeyDataSizeIllegal = errors.New("encrypted private key data size illegal")
+	errPemDekInfoIllegal           = errors.New("pem dek-info illegal")
+	errPemNotEncrypted             = errors.New("pem is not encrypted")
+)
+
+// EncryptedPrivateKeyInfo of RFC 5208.
+type encryptedPrivateKeyInfo struct {
+	Algorithm     pkix.AlgorithmIdentifier
+	EncryptedData []byte
+}
+
+// PBES2-params of RFC 8018.
+type pbes2Params struct {
+	KeyDerivationFunc pkix.AlgorithmIdentifier
+	EncryptionScheme  pkix.AlgorithmIdentifier
+}
+
+// PBKDF2-params of RFC 8018.
+type pbkdf2Params struct {
+	Salt           []byte
+	IterationCount int
+	KeyLength      int                      `asn1:"optional"`
+	Prf            pkix.AlgorithmIdentifier `asn1:"optional"`
+}
+
+// Marshal the private key to PKCS#8 EncryptedPrivateKeyInfo DER.
+// It uses PBES2 with PBKDF2 and AES-CBC, which can be read by OpenSSL and Java.
+//
+// password:
+// The password to derive the key.
+//
+// keySize:
+// The key size of AES, Aes128KeySize, Aes192KeySize or Aes256KeySize.
+//
+// prf:
+// The hash of HMAC used by PBKDF2, crypto.SHA1 or crypto.SHA256.
+//
+// iterations:
+// The iteration count of PBKDF2. If it is 0, Pkcs8DefaultIterations is used.
+// It must not be greater than Pkcs8MaxIterations.
+func (p RsaPrivate) MarshalEncryptedPkcs8PrivateKeyDer(password []byte, keySize int, prf crypto.Hash, iterations int) ([]byte, error) {
+	der, err := p.MarshalPkcs8PrivateKeyDer()
+	if err != nil {
+		return nil, err
+	}
+	if iterations == 0 {
+		iterations = Pkcs8DefaultIterations
+	}
+	if iterations < 0 || iterations > Pkcs8MaxIterations {
+		return nil, errPkcs8IterationsIllegal
+	}
+	encOid := pkcs8AesCbcOid(keySize)
+	if encOid == nil {
+		return nil, errPkcs8EncryptionUnsupported
+	}
+	prfOid, newHash := pkcs8PrfOid(prf)
+	if prfOid == nil {
+		return nil, errPkcs8PrfUnsupported
+	}
+	salt := make([]byte, pkcs8SaltSize)
+	iv := make([]byte, AesIvSize)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return nil, err
+	}
+	if _, err := io.ReadFull(rand.Reader, iv); err != nil {
+		return nil, err
+	}
+
+	key, err := pbkdf2.Key(newHash, string(password), salt, iterations, keySize)
+	if err != nil {
+		return nil, err
+	}
+	enc, err := NewAesCbcEncrypter(key, iv, NewPkcs7Padding(AesBlockSize)).Encrypt(der)
+	if err != nil {
+		return nil, err
+	}
+
+	kdfParams, err := asn1.Marshal(pbkdf2Params{
+		Salt:           salt,
+		IterationCount: iterations,
+		Prf: pkix.AlgorithmIdentifier{
+			Algorithm:  prfOid,
+			Parameters: asn1.NullRawValue,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	ivParams, err := asn1.Marshal(iv)
+	if err != nil {
+		return nil, err
+	}
+	params, err := asn1.Marshal(pbes2Params{
+		KeyDerivationFunc: pkix.AlgorithmIdentifier{
+			Algorithm:  oidPbkdf2,
+			Parameters: asn1.RawValue{FullBytes: kdfParams},
+		},
+		EncryptionScheme: pkix.AlgorithmIdentifier{
+			Algorithm:  encOid,
+			Parameters: asn1.RawValue{FullBytes: ivParams},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return asn1.Marshal(encryptedPrivateKeyInfo{
+		Algorithm: pkix.AlgorithmIdentifier{
+			Algorithm:  oidPbes2,
+			Parameters: asn1.RawValue{FullBytes: params},
+		},
+		EncryptedData: enc,
+	})
+}
+
+// Marshal the private key to PKCS#8 PEM, whose type is "ENCRYPTED PRIVATE KEY".
+// See MarshalEncryptedPkcs8PrivateKeyDer for the params.
+func (p RsaPrivate) MarshalEncryptedPkcs8PrivateKeyPem(password []byte, keySize int, prf crypto.Hash, iterations int) ([]byte, error) {
+	der, err := p.MarshalEncryptedPkcs8PrivateKeyDer(password, keySize, prf, iterations)
+	if err != nil {
+		return nil, err
+	}
+	return encodePem(PemTypeEncryptedPrivateKey, der), nil
+}
+
+// Parse the first encrypted private key block in data. It supports:
+//
+// "ENCRYPTED PRIVATE KEY": PKCS#8 with PBES2, PBKDF2 of HMAC-SHA1 or HMAC-SHA256, and AES-CBC.
+//
+// "RSA PRIVATE KEY" or "PRIVATE KEY" with the legacy OpenSSL "Proc-Type: 4,ENCRYPTED" header:
+// AES-128-CBC, AES-192-CBC, AES-256-CBC or DES-EDE3-CBC in the "DEK-Info" header.
+//
+// The other blocks are skipped.
+func ParseEncryptedRsaPrivatePem(data, password []byte) (RsaPrivate, error) {
+	block := decodePem(data, PemTypeEncryptedPrivateKey, PemTypeRsaPrivateKey, PemTypePrivateKey)
+	if block == nil {
+		return RsaPrivate{err: errRsaPemNoKey}, errRsaPemNoKey
+	}
+	if block.Type == PemTypeEncryptedPrivateKey {
+		return ParseEncryptedRsaPrivateDer(block.Bytes, password)
+	}
+	der, err := decryptLegacyPemBlock(block, password)
+	if err != nil {
+		return RsaPrivate{err: err}, err
+	}
+	if block.Type == PemTypeRsaPrivateKey {
+		return parseDecryptedRsaPrivateDer(parseRsaPrivatePkcs1Der(der))
+	}
+	return parseDecryptedRsaPrivateDer(parseRsaPrivatePkcs8Der(der))
+}
+
+// Parse PKCS#8 EncryptedPrivateKeyInfo DER.
+// See ParseEncryptedRsaPrivatePem for the supported algorithms.
+func ParseEncryptedRsaPrivateDer(der, password []byte) (RsaPrivate, error) {
+	plain, err := decryptPkcs8(der, password)
+	if err != nil {
+		return RsaPrivate{err: err}, err
+	}
+	return parseDecryptedRsaPrivateDer(parseRsaPrivatePkcs8Der(plain))
+}
+
+// A wrong password may produce the right padding by chance, then the decrypted DER can not be parsed.
+// Report it as the wrong password, except that the key is well formed but not RSA.
+func parseDecryptedRsaPrivateDer(pri RsaPrivate, err error) (RsaPrivate, error) {
+	if err != nil && err != errRsaKeyIsNotRsa {
+		return RsaPrivate{err: errPkcs8PasswordIncorrect}, errPkcs8PasswordIncorrect
+	}
+	return pri, err
+}
+
+func decryptPkcs8(der, password []byte) ([]byte, error) {
+	var info encryptedPrivateKeyInfo
+	if rest, err := asn1.Unmarshal(der, &info); err != nil || len(rest) != 0 || !info.Algorithm.Algorithm.Equal(oidPbes2) {
+		return nil, errPkcs8EncryptionUnsupported
+	}
+	var params pbes2Params
+	if _, err := asn1.Unmarshal(info.Algorithm.Parameters.FullBytes, &params); err != nil || !params.KeyDerivationFunc.Algorithm.Equal(oidPbkdf2) {
+		return nil, errPkcs8EncryptionUnsupported
+	}
+	var kdfParams pbkdf2Params
+	if _, err := asn1.Unmarshal(params.KeyDerivationFunc.Parameters.FullBytes, &kdfParams); err != nil {
+		return nil, errPkcs8EncryptionUnsupported
+	}
+	if kdfParams.IterationCount <= 0 || kdfParams.IterationCount > Pkcs8MaxIterations {
+		return nil, errPkcs8IterationsIllegal
+	}
+	newHash, ok := pkcs8PrfHash(kdfParams.Prf.Algorithm)
+	if !ok {
+		return nil, errPkcs8PrfUnsupported
+	}
+	keySize := pkcs8AesCbcKeySize(params.EncryptionScheme.Algorithm)
+	if keySize == 0 || (kdfParams.KeyLength != 0 && kdfParams.KeyLength != keySize) {
+		return nil, errPkcs8EncryptionUnsupported
+	}
+	var iv []byte
+	if _, err := asn1.Unmarshal(params.EncryptionScheme.Parameters.FullBytes, &iv); err != nil {
+		return nil, errPkcs8EncryptionUnsupported
+	}
+	if len(info.EncryptedData) == 0 || len(info.EncryptedData)%AesBlockSize != 0 {
+		return nil, errEncryptedKeyDataSizeIllegal
+	}
+
+	key, err := pbkdf2.Key(newHash, string(password), kdfParams.Salt, kdfParams.IterationCount, keySize)
+	if err != nil {
+		return nil, err
+	}
+	decrypter := NewAesCbcDecrypter(key, iv, NewPkcs7Padding(AesBlockSize))
+	if err, ok := decrypter.HasError(); ok {
+		return nil, err
+	}
+	plain, err := decrypter.Decrypt(info.EncryptedData)
+	if err != nil { // The size is checked, so only the padding can be wrong.
+		return nil, errPkcs8PasswordIncorrect
+	}
+	return plain, nil
+}
+
+// Decrypt the block with the legacy OpenSSL "Proc-Type" and "DEK-Info" headers.
+func decryptLegacyPemBlock(block *pem.Block, password []byte) ([]byte, error) {
+	if block.Headers["Proc-Type"] != "4,ENCRYPTED" {
+		return nil, errPemNotEncrypted
+	}
+	dekInfo := strings.SplitN(block.Headers["DEK-Info"], ",", 2)
+	if len(dekInfo) != 2 {
+		return nil, errPemDekInfoIllegal
+	}
+	iv, err := hex.DecodeString(dekInfo[1])
+	if err != nil {
+		return nil, errPemDekInfoIllegal
+	}
+	var plain []byte
+	switch dekInfo[0] {
+	case "AES-128-CBC", "AES-192-CBC", "AES-256-CBC":
+		keySize := map[string]int{
+			"AES-128-CBC": Aes128KeySize,
+			"AES-192-CBC": Aes192KeySize,
+			"AES-256-CBC": Aes256KeySize,
+		}[dekInfo[0]]
+		if len(iv) != AesIvSize {
+			return nil, errPemDekInfoIllegal
+		}
+		if len(block.Bytes) == 0 || len(block.Bytes)%AesBlockSize != 0 {
+			return nil, errEncryptedKeyDataSizeIllegal
+		}
+		key := evpBytesToKey(password, iv[:8], keySize)
+		plain, err = NewAesCbcDecrypter(key, iv, NewPkcs7Padding(AesBlockSize)).Decrypt(block.Bytes)
+	case "DES-EDE3-CBC":
+		if len(iv) != des.BlockSize {
+			return nil, errPemDekInfoIllegal
+		}
+		if len(block.Bytes) == 0 || len(block.Bytes)%des.BlockSize != 0 {
+			return nil, errEncryptedKeyDataSizeIllegal
+		}
+		plain, err = decryptDes3Cbc(evpBytesToKey(password, iv, 24), iv, block.Bytes)
+	default:
+		return nil, errPkcs8EncryptionUnsupported
+	}
+	if err != nil { // The size is checked, so only the padding can be wrong.
+		return nil, errPkcs8PasswordIncorrect
+	}
+	return plain, nil
+}
+
+func decryptDes3Cbc(key, iv, src []byte) ([]byte, error) {
+	block, err := des.NewTripleDESCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(src)%des.BlockSize != 0 {
+		return nil, errEncryptedKeyDataSizeIllegal
+	}
+	dst := make([]byte, len(src))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(dst, src)
+	return NewPkcs5Padding().Unpad(dst)
+}
+
+func pkcs8AesCbcOid(keySize int) asn1.ObjectIdentifier {
+	switch keySize {
+	case Aes128KeySize:
+		return oidAes128Cbc
+	case Aes192KeySize:
+		return oidAes192Cbc
+	case Aes256KeySize:
+		return oidAes256Cbc
+	}
+	return nil
+}
+
+// Return 0 if the oid is unsupported.
+func pkcs8AesCbcKeySize(oid asn1.ObjectIdentifier) int {
+	switch {
+	case oid.Equal(oidAes128Cbc):
+		return Aes128KeySize
+	case oid.Equal(oidAes192Cbc):
+		return Aes192KeySize
+	case oid.Equal(oidAes256Cbc):
+		return Aes256KeySize
+	}
+	return 0
+}
+
+func pkcs8PrfOid(prf crypto.Hash) (asn1.ObjectIdentifier, func() hash.Hash) {
+	switch prf {
+	case crypto.SHA1:
+		return oidHmacWithSha1, prf.New
+	case crypto.SHA256:
+		return oidHmacWithSha256, prf.New
+	}
+	return nil, nil
+}
+
+// The prf is hmacWithSHA1 by default if it is absent.
+func pkcs8PrfHash(oid asn1.ObjectIdentifier) (func() hash.Hash, bool) {
+	switch {
+	case len(oid) == 0, oid.Equal(oidHmacWithSha1):
+		return crypto.SHA1.New, true
+	case oid.Equal(oidHmacWithSha256):
+		return crypto.SHA256.New, true
+	}
+	return nil, false
+}
+
+// EVP_BytesToKey of OpenSSL with MD5 and one iteration, which is used by the legacy PEM encryption.
+func evpBytesToKey(password, salt []byte, size int) []byte {
+	var result, prev []byte
+	for len(result) < size {
+		h := md5.New()
+		h.Write(prev)
+		h.Write(password)
+		h.Write(salt)
+		prev = h.Sum(nil)
+		result = append(result, prev...)
+	}
+	return result[:size]
+}
@@ -0,0 +1,219 @@
+package crypt
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"io"
+	"sync"
+	"time"
+)
+
+const (
+	// A worker waits before retrying a failed generation, and the wait doubles up to the max.
+	rsaKeyPoolMinBackoff = 10 * time.Millisecond
+	rsaKeyPoolMaxBackoff = time.Second
+	// The pool stops if a worker fails so many times in a row.
+	rsaKeyPoolMaxFailures = 5
+)
+
+var (
+	errRsaKeyPoolClosed        = errors.New("rsa key pool closed")
+	errRsaKeyPoolTargetIllegal = errors.New("rsa key pool target illegal")
+)
+
+// The metrics of RsaKeyPool.
+type RsaKeyPoolStats struct {
+	Depth               int           // The number of keys which are ready to be got.
+	Target              int           // The number of keys which the pool refills to.
+	Generated           uint64        // The number of keys which have been generated.
+	Served              uint64        // The number of keys which have been got.
+	Failures            uint64        // The number of failed generations.
+	LastGenerationTime  time.Duration // The time of the last successful generation.
+	TotalGenerationTime time.Duration // The total time of the successful generations.
+}
+
+// It generates RSA keys in background goroutines, so that getting a key is fast.
+// It is safe for concurrent use. Call Close to stop the goroutines.
+//
+// If a worker fails to generate keys rsaKeyPoolMaxFailures times in a row, the pool stops,
+// and Get and HasError return the last generation error.
+//
+// It may has an error, call HasError to see it.
+type RsaKeyPool struct {
+	random  io.Reader
+	bits    int
+	e       int
+	keys    chan RsaPrivate
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	mu      sync.Mutex
+	stats   RsaKeyPoolStats
+	err     error
+	failErr error // The error which stops the pool, protected by mu.
+}
+
+// bits, e:
+// The same as NewRsaPrivate.
+//
+// target:
+// The number of keys which are kept ready. It must be greater than 0.
+//
+// workers:
+// The number of goroutines which generate keys. If it is 0, 1 is used.
+//
+// Can call HasError to see if it has an error.
+func NewRsaKeyPool(bits, e, target, workers int) *RsaKeyPool {
+	return newRsaKeyPool(rand.Reader, bits, e, target, workers)
+}
+
+func newRsaKeyPool(random io.Reader, bits, e, target, workers int) *RsaKeyPool {
+	pool := &RsaKeyPool{
+		random: random,
+		bits:   bits,
+		e:      e,
+	}
+	// The same as GenerateRsaPrivate with 2 primes.
+	if bits%8 != 0 || bits < 16*2 {
+		pool.err = errRsaPublicKeySize
+		return pool
+	}
+	if err := checkRsaPublicExponent(e); err != nil {
+		pool.err = err
+		return pool
+	}
+	if target <= 0 || workers < 0 {
+		pool.err = errRsaKeyPoolTargetIllegal
+		return pool
+	}
+	if workers == 0 {
+		workers = 1
+	}
+	pool.keys = make(chan RsaPrivate, target)
+	pool.ctx, pool.cancel = context.WithCancel(context.Background())
+	pool.stats.Target = target
+	pool.wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go pool.work()
+	}
+	return pool
+}
+
+func (p *RsaKeyPool) HasError() (error, bool) {
+	if p.err != nil {
+		return p.err, true
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.failErr, p.failErr != nil
+}
+
+func (p *RsaKeyPool) work() {
+	defer p.wg.Done()
+	backoff, failures := rsaKeyPoolMinBackoff, 0
+	for {
+		start := time.Now()
+		key, err := generateMultiPrimeKey(p.ctx, p.random, 2, p.bits, p.e)
+		if p.ctx.Err() != nil {
+			return
+		}
+		p.mu.Lock()
+		if err != nil {
+			p.stats.Failures++
+		} else {
+			elapsed := time.Since(start)
+			p.stats.Generated++
+			p.stats.LastGenerationTime = elapsed
+			p.stats.TotalGenerationTime += elapsed
+		}
+		p.mu.Unlock()
+		if err != nil {
+			failures++
+			if failures >= rsaKeyPoolMaxFailures {
+				p.fail(err)
+				return
+			}
+			// Do not retry at once, the failure may not go away soon.
+			select {
+			case <-time.After(backoff):
+			case <-p.ctx.Done():
+				return
+			}
+			if backoff *= 2; backoff > rsaKeyPoolMaxBackoff {
+				backoff = rsaKeyPoolMaxBackoff
+			}
+			continue
+		}
+		backoff, failures = rsaKeyPoolMinBackoff, 0
+		// It blocks when the pool is full, until a key is got or the pool is closed.
+		select {
+		case p.keys <- RsaPrivate{priKey: key}:
+		case <-p.ctx.Done():
+			return
+		}
+	}
+}
+
+// Get a key from the pool. If the pool is empty, wait until a key is generated.
+// Return ctx.Err() if ctx is done before that.
+func (p *RsaKeyPool) Get(ctx context.Context) (RsaPrivate, error) {
+	if p.err != nil {
+		return RsaPrivate{err: p.err}, p.err
+	}
+	if p.ctx.Err() != nil {
+		err := p.closedErr()
+		return RsaPrivate{err: err}, err
+	}
+	select {
+	case key := <-p.keys:
+		p.mu.Lock()
+		p.stats.Served++
+		p.mu.Unlock()
+		return key, nil
+	case <-ctx.Done():
+		return RsaPrivate{err: ctx.Err()}, ctx.Err()
+	case <-p.ctx.Done():
+		err := p.closedErr()
+		return RsaPrivate{err: err}, err
+	}
+}
+
+// Record err and stop the pool. Only the first error is kept.
+func (p *RsaKeyPool) fail(err error) {
+	p.mu.Lock()
+	if p.failErr == nil {
+		p.failErr = err
+	}
+	p.mu.Unlock()
+	p.cancel()
+}
+
+// The error of Get after the pool stops.
+func (p *RsaKeyPool) closedErr() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.failErr != nil {
+		return p.failErr
+	}
+	return errRsaKeyPoolClosed
+}
+
+// Return a snapshot of the metrics.
+func (p *RsaKeyPool) Stats() RsaKeyPoolStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	stats := p.stats
+	stats.Depth = len(p.keys)
+	return stats
+}
+
+// Stop the background goroutines and wait for them to exit. The keys in the pool are dropped.
+// The later Get returns an error. Close more than once does nothing.
+func (p *RsaKeyPool) Close() {
+	if p.err != nil {
+		return
+	}
+	p.cancel()
+	p.wg.Wait()
+}
@@ -0,0 +1,101 @@
+package crypt
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRsaKeyPool(t *testing.T) {
+	pool := NewRsaKeyPool(1024, 65537, 2, 2)
+	if err, ok := pool.HasError(); ok {
+		t.Fatal("new pool err:", err)
+	}
+	defer pool.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	var ns [][]byte
+	for i := 0; i < 3; i++ {
+		pri, err := pool.Get(ctx)
+		if err != nil {
+			t.Fatal("get err:", err)
+		}
+		if pri.Bits() != 1024 {
+			t.Error("the key bits is wrong:", pri.Bits())
+		}
+		for _, n := range ns {
+			if bytes.Equal(n, pri.GetNBytes()) {
+				t.Error("the same key is got twice")
+			}
+		}
+		ns = append(ns, pri.GetNBytes())
+	}
+
+	for pool.Stats().Depth < 2 {
+		time.Sleep(10 * time.Millisecond)
+	}
+	stats := pool.Stats()
+	if stats.Target != 2 || stats.Served != 3 || stats.Generated < 5 || stats.TotalGenerationTime <= 0 {
+		t.Error("stats is wrong:", stats)
+	}
+}
+
+func TestRsaKeyPoolCloseAndCancel(t *testing.T) {
+	pool := NewRsaKeyPool(4096, 65537, 1, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := pool.Get(ctx); err != context.Canceled {
+		t.Error("get with cancelled context err:", err)
+	}
+	pool.Close()
+	pool.Close()
+	if _, err := pool.Get(context.Background()); err != errRsaKeyPoolClosed {
+		t.Error("get from closed pool err:", err)
+	}
+	if _, ok := NewRsaKeyPool(1024, 65537, 0, 1).HasError(); !ok {
+		t.Error("illegal target should have error")
+	}
+}
+
+func TestRsaKeyPoolIllegalBits(t *testing.T) {
+	for _, bits := range []int{0, -8, 7, 24} {
+		pool := NewRsaKeyPool(bits, 65537, 1, 1)
+		if err, ok := pool.HasError(); !ok || err != errRsaPublicKeySize {
+			t.Error("illegal bits should have error:", bits, err)
+		}
+		pool.Close()
+	}
+}
+
+type failingReader struct{}
+
+var errFailingReader = errors.New("failing reader")
+
+func (failingReader) Read(p []byte) (int, error) {
+	return 0, errFailingReader
+}
+
+func TestRsaKeyPoolStopsAfterFailures(t *testing.T) {
+	pool := newRsaKeyPool(failingReader{}, 1024, 65537, 1, 2)
+	defer pool.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	start := time.Now()
+	if _, err := pool.Get(ctx); err != errFailingReader {
+		t.Fatal("get from failing pool err:", err)
+	}
+	// The workers back off between the failures instead of spinning.
+	if elapsed := time.Since(start); elapsed < rsaKeyPoolMinBackoff*(1<<(rsaKeyPoolMaxFailures-1)-1) {
+		t.Error("the failures are retried too fast:", elapsed)
+	}
+	if err, ok := pool.HasError(); !ok || err != errFailingReader {
+		t.Error("failing pool should have error:", err)
+	}
+	pool.wg.Wait()
+	if failures := pool.Stats().Failures; failures < rsaKeyPoolMaxFailures || failures > 2*rsaKeyPoolMaxFailures {
+		t.Error("the failures is wrong:", failures)
+	}
+}
@@ -0,0 +1,114 @@
+package crypt
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+)
+
+const (
+	// The salt length is as large as possible when signing, and auto-detected when verifying.
+	RsaPssSaltLengthAuto = rsa.PSSSaltLengthAuto
+	// The salt length equals to the size of hash.
+	RsaPssSaltLengthEqualsHash = rsa.PSSSaltLengthEqualsHash
+)
+
+var errRsaHashUnavailable = errors.New("rsa hash unavailable")
+
+// Sign of PKCS#1 v1.5. The message is hashed by hash first.
+//
+// hash:
+// Such as crypto.SHA256. It must be linked into the binary.
+func (p RsaPrivate) SignPkcs1v15(message []byte, hash crypto.Hash) ([]byte, error) {
+	digest, err := hashMessage(message, hash)
+	if err != nil {
+		return nil, err
+	}
+	return p.SignPkcs1v15Digest(digest, hash)
+}
+
+// Sign of PKCS#1 v1.5 with the precomputed digest.
+//
+// hash:
+// The hash which the digest is computed with. If it is 0, the digest is signed directly,
+// which is only useful for the legacy protocols such as TLS 1.0.
+func (p RsaPrivate) SignPkcs1v15Digest(digest []byte, hash crypto.Hash) ([]byte, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+	return rsa.SignPKCS1v15(rand.Reader, p.priKey, hash, digest)
+}
+
+// Sign of PSS. The message is hashed by hash first.
+//
+// hash:
+// Such as crypto.SHA256. It must be linked into the binary.
+//
+// saltLength:
+// The byte size of salt, or RsaPssSaltLengthAuto, or RsaPssSaltLengthEqualsHash.
+func (p RsaPrivate) SignPss(message []byte, hash crypto.Hash, saltLength int) ([]byte, error) {
+	digest, err := hashMessage(message, hash)
+	if err != nil {
+		return nil, err
+	}
+	return p.SignPssDigest(digest, hash, saltLength)
+}
+
+// Sign of PSS with the precomputed digest. See SignPss for the params.
+func (p RsaPrivate) SignPssDigest(digest []byte, hash crypto.Hash, saltLength int) ([]byte, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+	return rsa.SignPSS(rand.Reader, p.priKey, hash, digest, &rsa.PSSOptions{SaltLength: saltLength})
+}
+
+// Verify the signature of PKCS#1 v1.5. The message is hashed by hash first.
+// Return nil if the signature is valid.
+func (pub *RsaPublic) VerifyPkcs1v15(message, sig []byte, hash crypto.Hash) error {
+	digest, err := hashMessage(message, hash)
+	if err != nil {
+		return err
+	}
+	return pub.VerifyPkcs1v15Digest(digest, sig, hash)
+}
+
+// Verify the signature of PKCS#1 v1.5 with the precomputed digest.
+// Return nil if the signature is valid.
+func (pub *RsaPublic) VerifyPkcs1v15Digest(digest, sig []byte, hash crypto.Hash) error {
+	if pub.err != nil {
+		return pub.err
+	}
+	return rsa.VerifyPKCS1v15(pub.pubKey, hash, digest, sig)
+}
+
+// Verify the signature of PSS. The message is hashed by hash first.
+// Return nil if the signature is valid.
+//
+// saltLength:
+// The byte size of salt, or RsaPssSaltLengthAuto to detect it, or RsaPssSaltLengthEqualsHash.
+func (pub *RsaPublic) VerifyPss(message, sig []byte, hash crypto.Hash, saltLength int) error {
+	digest, err := hashMessage(message, hash)
+	if err != nil {
+		return err
+	}
+	return pub.VerifyPssDigest(digest, sig, hash, saltLength)
+}
+
+// Verify the signature of PSS with the precomputed digest. See VerifyPss for the params.
+// Return nil if the signature is valid.
+func (pub *RsaPublic) VerifyPssDigest(digest, sig []byte, hash crypto.Hash, saltLength int) error {
+	if pub.err != nil {
+		return pub.err
+	}
+	return rsa.VerifyPSS(pub.pubKey, hash, digest, sig, &rsa.PSSOptions{SaltLength: saltLength})
+}
+
+func hashMessage(message []byte, hash crypto.Hash) ([]byte, error) {
+	if hash == 0 || !hash.Available() {
+		return nil, errRsaHashUnavailable
+	}
+	h := hash.New()
+	h.Write(message)
+	return h.Sum(nil), nil
+}
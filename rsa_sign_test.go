@@ -0,0 +1,70 @@
+package crypt
+
+import (
+	"crypto"
+	"crypto/sha256"
+	"testing"
+)
+
+func TestRsaPkcs1v15SignAndVerify(t *testing.T) {
+	bits, e := 1024, 65537
+	pri := NewRsaPrivate(bits, e)
+	pub := NewRsaPublic(pri.GetNBytes(), e)
+	message := []byte("I love this girl! Does she?")
+
+	sig, err := pri.SignPkcs1v15(message, crypto.SHA256)
+	if err != nil {
+		t.Fatal("sign err:", err)
+	}
+	if err := pub.VerifyPkcs1v15(message, sig, crypto.SHA256); err != nil {
+		t.Error("verify err:", err)
+	}
+	digest := sha256.Sum256(message)
+	if err := pub.VerifyPkcs1v15Digest(digest[:], sig, crypto.SHA256); err != nil {
+		t.Error("verify digest err:", err)
+	}
+	if err := pub.VerifyPkcs1v15([]byte("other"), sig, crypto.SHA256); err == nil {
+		t.Error("verify other message should have error")
+	}
+	if err := pub.VerifyPkcs1v15(message, sig, crypto.SHA1); err == nil {
+		t.Error("verify with other hash should have error")
+	}
+}
+
+func TestRsaPssSignAndVerify(t *testing.T) {
+	bits, e := 1024, 65537
+	pri := NewRsaPrivate(bits, e)
+	pub := NewRsaPublic(pri.GetNBytes(), e)
+	message := []byte("I love this girl! Does she?")
+	digest := sha256.Sum256(message)
+
+	for _, saltLength := range []int{RsaPssSaltLengthEqualsHash, RsaPssSaltLengthAuto, 20} {
+		sig, err := pri.SignPssDigest(digest[:], crypto.SHA256, saltLength)
+		if err != nil {
+			t.Fatal("sign err:", saltLength, err)
+		}
+		if err := pub.VerifyPss(message, sig, crypto.SHA256, saltLength); err != nil {
+			t.Error("verify err:", saltLength, err)
+		}
+		if err := pub.VerifyPss(message, sig, crypto.SHA256, RsaPssSaltLengthAuto); err != nil {
+			t.Error("verify with auto salt length err:", saltLength, err)
+		}
+		sig[0] ^= 1
+		if err := pub.VerifyPss(message, sig, crypto.SHA256, saltLength); err == nil {
+			t.Error("verify tampered signature should have error:", saltLength)
+		}
+	}
+	if _, err := pri.SignPss(message, crypto.MD4, RsaPssSaltLengthAuto); err == nil {
+		t.Error("unavailable hash should have error")
+	}
+}
+
+func BenchmarkRsa1024SignPkcs1v15(b *testing.B) {
+	b.StopTimer()
+	pri := NewRsaPrivate(1024, 65537)
+	message := []byte("I love this girl! Does she?")
+	b.StartTimer()
+	for i := 0; i < b.N; i++ {
+		pri.SignPkcs1v15(message, crypto.SHA256)
+	}
+}
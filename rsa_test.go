@@ -2,6 +2,11 @@ package crypt
 
 import (
 	"bytes"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"math/big"
 	"testing"
 )
 
@@ -24,6 +29,271 @@ func TestRsaPkcs1v15PublicEncryptAndPrivateDecrypt(t *testing.T) {
 	}
 }
 
+func TestRsaPkcs1v15Parallel(t *testing.T) {
+	bits, e := 1024, 65537
+	pri := NewRsaPrivate(bits, e)
+	pub := NewRsaPublic(pri.GetNBytes(), e)
+	input := make([]byte, 10000)
+	for i := range input {
+		input[i] = byte(i)
+	}
+
+	for _, workers := range []int{0, 1, 3, 1000} {
+		enc, err := pub.PublicEncryptPkcs1v15Parallel(input, workers)
+		if err != nil {
+			t.Fatal("public encrypt err:", workers, err)
+		}
+		dec, err := pri.PrivateDecryptPkcs1v15(enc)
+		if err != nil || !bytes.Equal(dec, input) {
+			t.Error("private decrypt err:", workers, err)
+		}
+		enc, _ = pub.PublicEncryptPkcs1v15(input)
+		dec, err = pri.PrivateDecryptPkcs1v15Parallel(enc, workers)
+		if err != nil || !bytes.Equal(dec, input) {
+			t.Error("parallel private decrypt err:", workers, err)
+		}
+		enc[len(enc)/2] ^= 1
+		if _, err := pri.PrivateDecryptPkcs1v15Parallel(enc, workers); err == nil {
+			t.Error("parallel private decrypt tampered data should have error:", workers)
+		}
+	}
+	if dec, err := pri.PrivateDecryptPkcs1v15Parallel(nil, 0); err != nil || len(dec) != 0 {
+		t.Error("parallel private decrypt empty data is wrong:", dec, err)
+	}
+}
+
+func TestRsaPkcs1v15PrivateEncryptAndPublicDecrypt(t *testing.T) {
+	bits, e := 1024, 65537
+	pri := NewRsaPrivate(bits, e)
+	pub := pri.Public()
+	input := make([]byte, 1000)
+	for i := range input {
+		input[i] = byte(i)
+	}
+
+	enc, err := pri.PrivateEncryptPkcs1v15(input)
+	if err != nil {
+		t.Fatal("private encrypt err:", err)
+	}
+	if len(enc) != (1000+bits/8-11-1)/(bits/8-11)*bits/8 {
+		t.Error("the encrypted size is wrong:", len(enc))
+	}
+	// Each block is the same as the signature of the raw data.
+	if err := rsa.VerifyPKCS1v15(pub.pubKey, 0, input[:bits/8-11], enc[:bits/8]); err != nil {
+		t.Error("verify the first block err:", err)
+	}
+	dec, err := pub.PublicDecryptPkcs1v15(enc)
+	if err != nil {
+		t.Fatal("public decrypt err:", err)
+	}
+	if !bytes.Equal(input, dec) {
+		t.Error("the decrypted is not equal to the input")
+	}
+	if dec, err := pub.PublicDecryptPkcs1v15(nil); err != nil || len(dec) != 0 {
+		t.Error("public decrypt empty data is wrong:", dec, err)
+	}
+
+	enc[bits/8+1] ^= 1
+	if _, err := pub.PublicDecryptPkcs1v15(enc); err != errRsaPkcs1v15Type1Padding {
+		t.Error("public decrypt tampered data err:", err)
+	}
+	if _, err := pub.PublicDecryptPkcs1v15(enc[:bits/8+1]); err != errRsaCiphertextIllegal {
+		t.Error("public decrypt truncated data err:", err)
+	}
+	// The data encrypted by the public key is of the type 2 padding.
+	enc, _ = pub.PublicEncryptPkcs1v15(input)
+	if _, err := pub.PublicDecryptPkcs1v15(enc); err == nil {
+		t.Error("public decrypt the public encrypted data should have error")
+	}
+}
+
+func TestRsaOaepPublicEncryptAndPrivateDecrypt(t *testing.T) {
+	bits, e := 1024, 65537
+	pri := NewRsaPrivate(bits, e)
+	pub := NewRsaPublic(pri.GetNBytes(), e)
+	input := bytes.Repeat([]byte{0}, 1000)
+	label := []byte("label")
+
+	for _, hashes := range [][2]crypto.Hash{
+		{crypto.SHA1, 0},
+		{crypto.SHA256, crypto.SHA1},
+		{crypto.SHA256, 0},
+		{crypto.SHA384, crypto.SHA256},
+	} {
+		hash, mgfHash := hashes[0], hashes[1]
+		enc, err := pub.PublicEncryptOaep(input, hash, mgfHash, label)
+		if err != nil {
+			t.Error("public encrypt err:", hash, mgfHash, err)
+			continue
+		}
+		eachSize := bits/8 - 2*hash.Size() - 2
+		if chunks := (len(input) + eachSize - 1) / eachSize; len(enc) != chunks*bits/8 {
+			t.Error("the encrypted length is wrong:", hash, mgfHash, len(enc))
+		}
+		dec, err := pri.PrivateDecryptOaep(enc, hash, mgfHash, label)
+		if err != nil {
+			t.Error("private decrypt err:", hash, mgfHash, err)
+		}
+		if !bytes.Equal(input, dec) {
+			t.Error("the decrypted is not equal to the input:", hash, mgfHash)
+		}
+		if _, err := pri.PrivateDecryptOaep(enc, hash, mgfHash, nil); err == nil {
+			t.Error("private decrypt with wrong label should have error:", hash, mgfHash)
+		}
+	}
+	if _, err := pub.PublicEncryptOaep(input, crypto.MD5, 0, nil); err == nil {
+		t.Error("unsupported hash should have error")
+	}
+	// SHA-512 leaves no room for data in a 1024 bits key.
+	if _, err := pub.PublicEncryptOaep(input, crypto.SHA512, 0, nil); err == nil {
+		t.Error("too large hash for the key should have error")
+	}
+}
+
+func TestNewRsaPublicIllegal(t *testing.T) {
+	pri := NewRsaPrivate(1024, 65537)
+	n := pri.GetNBytes()
+	if err, ok := NewRsaPublic(n, 65537).HasError(); ok {
+		t.Fatal("new rsa public err:", err)
+	}
+
+	evenN := append([]byte(nil), n...)
+	evenN[len(evenN)-1] &^= 1
+	for name, pub := range map[string]RsaPublic{
+		"empty n":     NewRsaPublic(nil, 65537),
+		"even n":      NewRsaPublic(evenN, 65537),
+		"small n":     NewRsaPublic(n[:64], 65537),
+		"min bits":    NewRsaPublicWithMinBits(n, 65537, 2048),
+		"e is 1":      NewRsaPublic(n, 1),
+		"even e":      NewRsaPublic(n, 65536),
+		"negative e":  NewRsaPublic(n, -3),
+		"too large e": NewRsaPublic(n, RsaMaxPublicExponent+1),
+	} {
+		if _, ok := pub.HasError(); !ok {
+			t.Error("should have error:", name)
+		}
+		if _, err := pub.PublicEncryptPkcs1v15([]byte("a")); err == nil {
+			t.Error("public encrypt should have error:", name)
+		}
+	}
+	if _, ok := NewRsaPrivate(1024, 4).HasError(); !ok {
+		t.Error("even e of private key should have error")
+	}
+}
+
+func TestNewRsaPrivateFromComponents(t *testing.T) {
+	origin := NewRsaPrivate(1024, 65537)
+	key := origin.priKey
+	input := []byte("I love this girl! Does she?")
+
+	pri := NewRsaPrivateFromComponents(key.N.Bytes(), key.E, key.D.Bytes(), [][]byte{key.Primes[0].Bytes(), key.Primes[1].Bytes()})
+	if err, ok := pri.HasError(); ok {
+		t.Fatal("new from components err:", err)
+	}
+	if err := pri.Validate(); err != nil {
+		t.Error("validate err:", err)
+	}
+	if pri.Bits() != 1024 || pri.Size() != 128 {
+		t.Error("bits or size is wrong:", pri.Bits(), pri.Size())
+	}
+	pub := pri.Public()
+	if pub.Bits() != 1024 || pub.Size() != 128 {
+		t.Error("public bits or size is wrong:", pub.Bits(), pub.Size())
+	}
+	enc, err := pub.PublicEncryptPkcs1v15(input)
+	if err != nil {
+		t.Fatal("public encrypt err:", err)
+	}
+	dec, err := origin.PrivateDecryptPkcs1v15(enc)
+	if err != nil || !bytes.Equal(dec, input) {
+		t.Error("private decrypt err:", err)
+	}
+
+	wrongD := new(big.Int).Add(key.D, bigOne).Bytes()
+	if _, ok := NewRsaPrivateFromComponents(key.N.Bytes(), key.E, wrongD, [][]byte{key.Primes[0].Bytes(), key.Primes[1].Bytes()}).HasError(); !ok {
+		t.Error("inconsistent components should have error")
+	}
+	if _, ok := NewRsaPrivateFromComponents(key.N.Bytes(), key.E, key.D.Bytes(), nil).HasError(); !ok {
+		t.Error("components without primes should have error")
+	}
+}
+
+func TestNewRsaPrivateFromKey(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal("generate key err:", err)
+	}
+	pri := NewRsaPrivateFromKey(key)
+	if err, ok := pri.HasError(); ok {
+		t.Fatal("new from key err:", err)
+	}
+	if !bytes.Equal(pri.GetNBytes(), key.N.Bytes()) {
+		t.Error("n is wrong")
+	}
+	if _, ok := NewRsaPrivateFromKey(nil).HasError(); !ok {
+		t.Error("nil key should have error")
+	}
+}
+
+type zeroReader struct{}
+
+func (zeroReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = 0
+	}
+	return len(p), nil
+}
+
+// A deterministic random source by AES-CTR of the seed.
+func newTestRandom(seed string) *AesStreamReader {
+	key := bytes.Repeat([]byte(seed), Aes128KeySize)[:Aes128KeySize]
+	return NewAesStreamReader(zeroReader{}, NewAesCtr(key, make([]byte, AesIvSize)))
+}
+
+func TestGenerateRsaPrivate(t *testing.T) {
+	ctx := context.Background()
+	input := bytes.Repeat([]byte{1}, 300)
+
+	pri1, err := GenerateRsaPrivate(ctx, newTestRandom("a"), 1024, 65537, 0)
+	if err != nil {
+		t.Fatal("generate err:", err)
+	}
+	pri2, err := GenerateRsaPrivate(ctx, newTestRandom("a"), 1024, 65537, 0)
+	if err != nil {
+		t.Fatal("generate err:", err)
+	}
+	if !bytes.Equal(pri1.GetNBytes(), pri2.GetNBytes()) {
+		t.Error("the same random source should generate the same key")
+	}
+
+	pri3, err := GenerateRsaPrivate(ctx, nil, 1536, 3, 3)
+	if err != nil {
+		t.Fatal("generate 3 primes err:", err)
+	}
+	if len(pri3.priKey.Primes) != 3 || pri3.Bits() != 1536 {
+		t.Error("the key of 3 primes is wrong:", len(pri3.priKey.Primes), pri3.Bits())
+	}
+	enc, err := pri3.PublicEncryptPkcs1v15(input)
+	if err != nil {
+		t.Fatal("public encrypt err:", err)
+	}
+	dec, err := pri3.PrivateDecryptPkcs1v15(enc)
+	if err != nil || !bytes.Equal(dec, input) {
+		t.Error("private decrypt with 3 primes err:", err)
+	}
+}
+
+func TestGenerateRsaPrivateCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := GenerateRsaPrivate(ctx, nil, 4096, 65537, 2); err != context.Canceled {
+		t.Error("generate with cancelled context err:", err)
+	}
+	if _, err := GenerateRsaPrivate(context.Background(), nil, 1024, 2, 2); err == nil {
+		t.Error("even e should have error")
+	}
+}
+
 func BenchmarkNewRsaPrivate(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		NewRsaPrivate(1024, 65537)
@@ -68,6 +338,50 @@ func BenchmarkRsa1024PrivateDecryptPkcs1v15With1000Bytes(b *testing.B) {
 	}
 }
 
+func BenchmarkRsa1024PrivateDecryptPkcs1v15With100KBytes(b *testing.B) {
+	b.StopTimer()
+	bits, e := 1024, 65537
+	pri := NewRsaPrivate(bits, e)
+	pub := NewRsaPublic(pri.GetNBytes(), e)
+	input := bytes.Repeat([]byte{0}, 100*1024)
+	enc, err := pub.PublicEncryptPkcs1v15(input)
+	if err != nil {
+		b.Error("public encrypt err:", err)
+	}
+	b.StartTimer()
+	for i := 0; i < b.N; i++ {
+		pri.PrivateDecryptPkcs1v15(enc)
+	}
+}
+
+func BenchmarkRsa1024PrivateDecryptPkcs1v15ParallelWith100KBytes(b *testing.B) {
+	b.StopTimer()
+	bits, e := 1024, 65537
+	pri := NewRsaPrivate(bits, e)
+	pub := NewRsaPublic(pri.GetNBytes(), e)
+	input := bytes.Repeat([]byte{0}, 100*1024)
+	enc, err := pub.PublicEncryptPkcs1v15(input)
+	if err != nil {
+		b.Error("public encrypt err:", err)
+	}
+	b.StartTimer()
+	for i := 0; i < b.N; i++ {
+		pri.PrivateDecryptPkcs1v15Parallel(enc, 0)
+	}
+}
+
+func BenchmarkRsa1024PublicEncryptPkcs1v15ParallelWith100KBytes(b *testing.B) {
+	b.StopTimer()
+	bits, e := 1024, 65537
+	pri := NewRsaPrivate(bits, e)
+	pub := NewRsaPublic(pri.GetNBytes(), e)
+	input := bytes.Repeat([]byte{0}, 100*1024)
+	b.StartTimer()
+	for i := 0; i < b.N; i++ {
+		pub.PublicEncryptPkcs1v15Parallel(input, 0)
+	}
+}
+
 func BenchmarkRsa1024GetNBytes(b *testing.B) {
 	b.StopTimer()
 	bits, e := 1024, 65537
@@ -77,3 +391,19 @@ func BenchmarkRsa1024GetNBytes(b *testing.B) {
 		pri.GetNBytes()
 	}
 }
+
+func BenchmarkRsa1024PrivateDecryptOaepWith1000Bytes(b *testing.B) {
+	b.StopTimer()
+	bits, e := 1024, 65537
+	pri := NewRsaPrivate(bits, e)
+	pub := NewRsaPublic(pri.GetNBytes(), e)
+	input := bytes.Repeat([]byte{0}, 1000)
+	enc, err := pub.PublicEncryptOaep(input, crypto.SHA256, 0, nil)
+	if err != nil {
+		b.Error("public encrypt err:", err)
+	}
+	b.StartTimer()
+	for i := 0; i < b.N; i++ {
+		pri.PrivateDecryptOaep(enc, crypto.SHA256, 0, nil)
+	}
+}
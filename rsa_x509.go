@@ -0,0 +1,217 @@
+package crypt
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"math/big"
+	"net"
+	"net/url"
+	"time"
+)
+
+const (
+	PemTypeCertificate        = "CERTIFICATE"         // X.509 certificate.
+	PemTypeCertificateRequest = "CERTIFICATE REQUEST" // PKCS#10 certificate signing request.
+)
+
+var (
+	errRsaCertificateNoCert      = errors.New("no certificate found in pem")
+	errRsaCertificateValidity    = errors.New("certificate not after is not after not before")
+	errRsaCertificateNotCa       = errors.New("certificate is not a ca")
+	errRsaCertificateKeyMismatch = errors.New("certificate public key mismatches the private key")
+)
+
+// The description of a certificate or a certificate signing request.
+// Only Subject and the subject alternative names are used by the certificate signing request.
+type RsaCertificateTemplate struct {
+	Subject pkix.Name
+
+	// The subject alternative names.
+	DnsNames       []string
+	IpAddresses    []net.IP
+	EmailAddresses []string
+	Uris           []*url.URL
+
+	// If NotBefore is zero, the current time is used. NotAfter must be after NotBefore.
+	NotBefore time.Time
+	NotAfter  time.Time
+
+	// If it is nil, a random 128 bits number is used.
+	SerialNumber *big.Int
+
+	// Whether the certificate can sign other certificates.
+	IsCa bool
+
+	// If it is 0, x509.KeyUsageDigitalSignature and x509.KeyUsageKeyEncipherment is used,
+	// and x509.KeyUsageCertSign is added if IsCa.
+	KeyUsage x509.KeyUsage
+
+	// If it is nil and not IsCa, x509.ExtKeyUsageServerAuth and x509.ExtKeyUsageClientAuth is used for mTLS.
+	ExtKeyUsage []x509.ExtKeyUsage
+}
+
+// Create a self-signed certificate of the private key in PEM, whose type is "CERTIFICATE".
+// The signature algorithm is SHA256 with RSA.
+func (p RsaPrivate) CreateSelfSignedCertificatePem(template RsaCertificateTemplate) ([]byte, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+	cert, err := newX509Certificate(template)
+	if err != nil {
+		return nil, err
+	}
+	der, err := x509.CreateCertificate(rand.Reader, cert, cert, &p.priKey.PublicKey, p.CryptoSigner())
+	if err != nil {
+		return nil, err
+	}
+	return encodePem(PemTypeCertificate, der), nil
+}
+
+// Create a certificate of pub signed by the private key of CA in PEM, whose type is "CERTIFICATE".
+// The signature algorithm is SHA256 with RSA.
+//
+// pub:
+// The public key of the certificate.
+//
+// caCertPem:
+// The certificate of CA in PEM. Its public key must be the public half of p.
+func (p RsaPrivate) CreateCertificatePem(template RsaCertificateTemplate, pub *RsaPublic, caCertPem []byte) ([]byte, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+	if pub.err != nil {
+		return nil, pub.err
+	}
+	caCert, err := parseX509CertificatePem(caCertPem)
+	if err != nil {
+		return nil, err
+	}
+	if !caCert.BasicConstraintsValid || !caCert.IsCA {
+		return nil, errRsaCertificateNotCa
+	}
+	if !p.priKey.PublicKey.Equal(caCert.PublicKey) {
+		return nil, errRsaCertificateKeyMismatch
+	}
+	cert, err := newX509Certificate(template)
+	if err != nil {
+		return nil, err
+	}
+	der, err := x509.CreateCertificate(rand.Reader, cert, caCert, pub.pubKey, p.CryptoSigner())
+	if err != nil {
+		return nil, err
+	}
+	return encodePem(PemTypeCertificate, der), nil
+}
+
+// Create a PKCS#10 certificate signing request of the private key in PEM, whose type is "CERTIFICATE REQUEST".
+// The signature algorithm is SHA256 with RSA.
+func (p RsaPrivate) CreateCertificateRequestPem(template RsaCertificateTemplate) ([]byte, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+	der, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		SignatureAlgorithm: x509.SHA256WithRSA,
+		Subject:            template.Subject,
+		DNSNames:           template.DnsNames,
+		IPAddresses:        template.IpAddresses,
+		EmailAddresses:     template.EmailAddresses,
+		URIs:               template.Uris,
+	}, p.CryptoSigner())
+	if err != nil {
+		return nil, err
+	}
+	return encodePem(PemTypeCertificateRequest, der), nil
+}
+
+// Parse the public key of the first certificate in PEM.
+// The certificate is not verified.
+// The bit size of N must not be less than RsaDefaultMinPublicKeyBits.
+func ParseRsaCertificatePem(data []byte) (RsaPublic, error) {
+	return ParseRsaCertificatePemWithMinBits(data, RsaDefaultMinPublicKeyBits)
+}
+
+// The same as ParseRsaCertificatePem, except that the minimum bit size of N is minBits,
+// such as 2048 to reject the weak keys in production.
+func ParseRsaCertificatePemWithMinBits(data []byte, minBits int) (RsaPublic, error) {
+	block := decodePem(data, PemTypeCertificate)
+	if block == nil {
+		return RsaPublic{err: errRsaCertificateNoCert}, errRsaCertificateNoCert
+	}
+	return ParseRsaCertificateDerWithMinBits(block.Bytes, minBits)
+}
+
+// Parse the public key of a certificate in DER.
+// The certificate is not verified.
+// The bit size of N must not be less than RsaDefaultMinPublicKeyBits.
+func ParseRsaCertificateDer(der []byte) (RsaPublic, error) {
+	return ParseRsaCertificateDerWithMinBits(der, RsaDefaultMinPublicKeyBits)
+}
+
+// The same as ParseRsaCertificateDer, except that the minimum bit size of N is minBits,
+// such as 2048 to reject the weak keys in production.
+func ParseRsaCertificateDerWithMinBits(der []byte, minBits int) (RsaPublic, error) {
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return RsaPublic{err: err}, err
+	}
+	pubKey, ok := cert.PublicKey.(*rsa.PublicKey)
+	if !ok {
+		return RsaPublic{err: errRsaKeyIsNotRsa}, errRsaKeyIsNotRsa
+	}
+	return newParsedRsaPublic(pubKey, minBits)
+}
+
+func newX509Certificate(template RsaCertificateTemplate) (*x509.Certificate, error) {
+	notBefore := template.NotBefore
+	if notBefore.IsZero() {
+		notBefore = time.Now()
+	}
+	if !template.NotAfter.After(notBefore) {
+		return nil, errRsaCertificateValidity
+	}
+	serialNumber := template.SerialNumber
+	if serialNumber == nil {
+		var err error
+		serialNumber, err = rand.Int(rand.Reader, new(big.Int).Lsh(bigOne, 128))
+		if err != nil {
+			return nil, err
+		}
+	}
+	keyUsage := template.KeyUsage
+	if keyUsage == 0 {
+		keyUsage = x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment
+		if template.IsCa {
+			keyUsage |= x509.KeyUsageCertSign
+		}
+	}
+	extKeyUsage := template.ExtKeyUsage
+	if extKeyUsage == nil && !template.IsCa {
+		extKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth}
+	}
+	return &x509.Certificate{
+		SignatureAlgorithm:    x509.SHA256WithRSA,
+		SerialNumber:          serialNumber,
+		Subject:               template.Subject,
+		DNSNames:              template.DnsNames,
+		IPAddresses:           template.IpAddresses,
+		EmailAddresses:        template.EmailAddresses,
+		URIs:                  template.Uris,
+		NotBefore:             notBefore,
+		NotAfter:              template.NotAfter,
+		KeyUsage:              keyUsage,
+		ExtKeyUsage:           extKeyUsage,
+		BasicConstraintsValid: true,
+		IsCA:                  template.IsCa,
+	}, nil
+}
+
+func parseX509CertificatePem(data []byte) (*x509.Certificate, error) {
+	block := decodePem(data, PemTypeCertificate)
+	if block == nil {
+		return nil, errRsaCertificateNoCert
+	}
+	return x509.ParseCertificate(block.Bytes)
+}
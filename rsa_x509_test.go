@@ -0,0 +1,133 @@
+package crypt
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestRsaSelfSignedCertificate(t *testing.T) {
+	pri := NewRsaPrivate(1024, 65537)
+	certPem, err := pri.CreateSelfSignedCertificatePem(RsaCertificateTemplate{
+		Subject:     pkix.Name{CommonName: "localhost"},
+		DnsNames:    []string{"localhost"},
+		IpAddresses: []net.IP{net.ParseIP("127.0.0.1")},
+		NotAfter:    time.Now().Add(time.Hour),
+	})
+	if err != nil {
+		t.Fatal("create err:", err)
+	}
+	cert := parseTestCertificate(t, certPem)
+	if cert.Subject.CommonName != "localhost" || len(cert.DNSNames) != 1 || len(cert.IPAddresses) != 1 || cert.IsCA {
+		t.Error("the certificate is wrong:", cert.Subject, cert.DNSNames, cert.IPAddresses)
+	}
+	if err := cert.CheckSignature(cert.SignatureAlgorithm, cert.RawTBSCertificate, cert.Signature); err != nil {
+		t.Error("check signature err:", err)
+	}
+	pub, err := ParseRsaCertificatePem(certPem)
+	if err != nil {
+		t.Fatal("parse err:", err)
+	}
+	if string(pub.pubKey.N.Bytes()) != string(pri.GetNBytes()) {
+		t.Error("the parsed public key is wrong")
+	}
+
+	if _, err := ParseRsaCertificatePemWithMinBits(certPem, 2048); err != errRsaPublicKeyTooSmall {
+		t.Error("parse with min bits 2048 err:", err)
+	}
+	block, _ := pem.Decode(certPem)
+	if _, err := ParseRsaCertificateDerWithMinBits(block.Bytes, 2048); err != errRsaPublicKeyTooSmall {
+		t.Error("parse der with min bits 2048 err:", err)
+	}
+
+	if _, err := pri.CreateSelfSignedCertificatePem(RsaCertificateTemplate{}); err != errRsaCertificateValidity {
+		t.Error("create without not after err:", err)
+	}
+	if _, err := ParseRsaCertificatePem([]byte("foo")); err != errRsaCertificateNoCert {
+		t.Error("parse illegal pem err:", err)
+	}
+}
+
+func TestRsaCaSignedCertificate(t *testing.T) {
+	caPri := NewRsaPrivate(1024, 65537)
+	caPem, err := caPri.CreateSelfSignedCertificatePem(RsaCertificateTemplate{
+		Subject:  pkix.Name{CommonName: "test ca"},
+		NotAfter: time.Now().Add(time.Hour),
+		IsCa:     true,
+	})
+	if err != nil {
+		t.Fatal("create ca err:", err)
+	}
+	pri := NewRsaPrivate(1024, 65537)
+	pub := pri.Public()
+	certPem, err := caPri.CreateCertificatePem(RsaCertificateTemplate{
+		Subject:  pkix.Name{CommonName: "client"},
+		DnsNames: []string{"client.test"},
+		NotAfter: time.Now().Add(time.Hour),
+	}, &pub, caPem)
+	if err != nil {
+		t.Fatal("create err:", err)
+	}
+
+	roots := x509.NewCertPool()
+	roots.AddCert(parseTestCertificate(t, caPem))
+	cert := parseTestCertificate(t, certPem)
+	if _, err := cert.Verify(x509.VerifyOptions{
+		DNSName:   "client.test",
+		Roots:     roots,
+		KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}); err != nil {
+		t.Error("verify err:", err)
+	}
+	if parsed, _ := ParseRsaCertificatePem(certPem); string(parsed.pubKey.N.Bytes()) != string(pri.GetNBytes()) {
+		t.Error("the parsed public key is wrong")
+	}
+
+	template := RsaCertificateTemplate{NotAfter: time.Now().Add(time.Hour)}
+	if _, err := pri.CreateCertificatePem(template, &pub, caPem); err != errRsaCertificateKeyMismatch {
+		t.Error("create by other key err:", err)
+	}
+	if _, err := pri.CreateCertificatePem(template, &pub, certPem); err != errRsaCertificateNotCa {
+		t.Error("create by not ca err:", err)
+	}
+}
+
+func TestRsaCertificateRequest(t *testing.T) {
+	pri := NewRsaPrivate(1024, 65537)
+	csrPem, err := pri.CreateCertificateRequestPem(RsaCertificateTemplate{
+		Subject:  pkix.Name{CommonName: "client", Organization: []string{"test"}},
+		DnsNames: []string{"client.test"},
+	})
+	if err != nil {
+		t.Fatal("create err:", err)
+	}
+	block, _ := pem.Decode(csrPem)
+	if block == nil || block.Type != PemTypeCertificateRequest {
+		t.Fatal("the pem is wrong")
+	}
+	csr, err := x509.ParseCertificateRequest(block.Bytes)
+	if err != nil {
+		t.Fatal("parse err:", err)
+	}
+	if err := csr.CheckSignature(); err != nil {
+		t.Error("check signature err:", err)
+	}
+	if csr.Subject.CommonName != "client" || len(csr.DNSNames) != 1 || csr.DNSNames[0] != "client.test" {
+		t.Error("the request is wrong:", csr.Subject, csr.DNSNames)
+	}
+}
+
+func parseTestCertificate(t *testing.T, certPem []byte) *x509.Certificate {
+	block, _ := pem.Decode(certPem)
+	if block == nil || block.Type != PemTypeCertificate {
+		t.Fatal("the pem is wrong")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		t.Fatal("parse certificate err:", err)
+	}
+	return cert
+}